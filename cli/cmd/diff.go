@@ -0,0 +1,115 @@
+/*
+Copyright © 2023 Gianpaolo Terranova <g.terranova@sazalex.com>
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice,
+    this list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+ 3. Neither the name of the copyright holder nor the names of its contributors
+    may be used to endorse or promote products derived from this software
+    without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"terra9.it/checkmate/core"
+)
+
+var diffJSON bool
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <package.chlx> <a.json> <b.json>",
+	Short: "Compare the outcomes of two answers files",
+	Long: `Load a checklist package and compare the outcomes of two answers files
+(data.json / ProjectExport): the answers changed, the derived tags changed
+and the sections of the rendered templates whose text changed.
+
+With --json the differences are printed as a JSON object. The command fails
+when the outcomes differ, like diff(1).`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := loadProject(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := readProjectExport(args[1])
+		if err != nil {
+			return err
+		}
+		b, err := readProjectExport(args[2])
+		if err != nil {
+			return err
+		}
+
+		diff, err := project.Diff(a, b)
+		if err != nil {
+			return err
+		}
+		if diffJSON {
+			data, err := json.MarshalIndent(diff, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		} else {
+			printDiff(cmd.OutOrStdout(), diff)
+		}
+		if !diff.Empty() {
+			return fmt.Errorf("the outcomes differ")
+		}
+		return nil
+	},
+}
+
+func printDiff(w io.Writer, diff *core.ProjectDiff) {
+	for _, group := range []struct {
+		title   string
+		changes []core.TagChange
+	}{{"answers", diff.Inputs}, {"derived tags", diff.Derived}} {
+		if len(group.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s:\n", group.title)
+		for _, c := range group.changes {
+			fmt.Fprintf(w, "  %s: %v -> %v\n", c.Tag, c.Old, c.New)
+		}
+	}
+	for _, s := range diff.Sections {
+		fmt.Fprintf(w, "template %s, section %q:\n", s.Template, s.Title)
+		for _, line := range s.Lines {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&projectLang, "lang", "", "language of the package texts and templates")
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "print the differences as JSON")
+}
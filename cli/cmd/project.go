@@ -0,0 +1,133 @@
+/*
+Copyright © 2023 Gianpaolo Terranova <g.terranova@sazalex.com>
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice,
+    this list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+ 3. Neither the name of the copyright holder nor the names of its contributors
+    may be used to endorse or promote products derived from this software
+    without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"terra9.it/checkmate/core"
+)
+
+var dataFile string
+var setValues []string
+var projectLang string
+
+// addProjectFlags registers the flags used to apply answers to a project.
+func addProjectFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&dataFile, "data", "d", "", "answers file (data.json / ProjectExport)")
+	cmd.Flags().StringArrayVarP(&setValues, "set", "s", nil, "set an answer as tag=value, or just tag to tick a checkbox")
+	cmd.Flags().StringVar(&projectLang, "lang", "", "language of the package texts and templates")
+}
+
+// loadProject opens the package, applies the answers given on the command
+// line and validates the result.
+func loadProject(filename string) (project *core.Project, err error) {
+	if !strings.HasSuffix(filename, ".chlx") {
+		filename += ".chlx"
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot load %s: %v", filename, r)
+		}
+	}()
+
+	if project, err = core.LoadProject(filename); err != nil {
+		return nil, err
+	}
+	if err = project.SetLanguage(projectLang); err != nil {
+		return nil, err
+	}
+
+	if dataFile != "" {
+		export, err := readProjectExport(dataFile)
+		if err != nil {
+			return nil, err
+		}
+		if err = project.LoadProjectData(export); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range setValues {
+		tag, value := parseSetValue(s)
+		if err = project.SetFeature(tag, value); err != nil {
+			return nil, fmt.Errorf("cannot set %s: %v", tag, err)
+		}
+	}
+
+	if _, err = project.Validate(""); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// readProjectExport reads an answers file.
+func readProjectExport(filename string) (core.ProjectExport, error) {
+	var export core.ProjectExport
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return export, err
+	}
+	if err = core.UnmarshalValues(data, &export); err != nil {
+		return export, fmt.Errorf("cannot read %s: %v", filename, err)
+	}
+	return export, nil
+}
+
+func parseSetValue(s string) (string, any) {
+	tag, raw, found := strings.Cut(s, "=")
+	if !found {
+		return tag, true
+	}
+	if b, err := strconv.ParseBool(raw); err == nil {
+		return tag, b
+	}
+	if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return tag, i
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return tag, f
+	}
+	return tag, raw
+}
+
+// writeOutput writes data to the named file, or to stdout when empty.
+func writeOutput(filename string, data []byte) error {
+	if filename == "" || filename == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2023 Gianpaolo Terranova <g.terranova@sazalex.com>
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice,
+    this list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+ 3. Neither the name of the copyright holder nor the names of its contributors
+    may be used to endorse or promote products derived from this software
+    without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var renderTemplate string
+var renderOutput string
+var renderList bool
+var renderText bool
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:   "render <package.chlx>",
+	Short: "Render a package template with the given answers",
+	Long: `Load a checklist package, apply the answers and render the template
+selected with --template to stdout or to the --output file.
+
+Use --list to print the templates defined by the package for the --lang
+language, --text to print the text produced by the template before any
+format conversion (the form used by the scenario snapshots).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := loadProject(args[0])
+		if err != nil {
+			return err
+		}
+
+		if renderList {
+			for _, t := range project.Templates() {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", t.Name, t.Format)
+			}
+			return nil
+		}
+
+		if renderTemplate == "" {
+			templates := project.Templates()
+			if len(templates) == 0 {
+				return fmt.Errorf("package %s has no templates", args[0])
+			}
+			renderTemplate = templates[0].Name
+		}
+		t := project.GetTemplateDef(renderTemplate)
+		if t == nil {
+			return fmt.Errorf("template %s not found", renderTemplate)
+		}
+
+		if renderText {
+			text, err := project.RenderText(t)
+			if err != nil {
+				return err
+			}
+			return writeOutput(renderOutput, []byte(text+"\n"))
+		}
+
+		report, err := project.Render(cmd.Context(), t)
+		if err != nil {
+			return err
+		}
+		if t.IsDocument() {
+			return writeOutput(renderOutput, report.Data)
+		}
+		return writeOutput(renderOutput, append(report.Data, '\n'))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+	addProjectFlags(renderCmd)
+
+	renderCmd.Flags().StringVarP(&renderTemplate, "template", "t", "", "name of the template to render (default is the first one)")
+	renderCmd.Flags().StringVarP(&renderOutput, "output", "o", "", "output file (default is stdout)")
+	renderCmd.Flags().BoolVarP(&renderList, "list", "l", false, "list the package templates")
+	renderCmd.Flags().BoolVar(&renderText, "text", false, "print the template text without converting it")
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2023 Gianpaolo Terranova <g.terranova@sazalex.com>
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice,
+    this list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+ 3. Neither the name of the copyright holder nor the names of its contributors
+    may be used to endorse or promote products derived from this software
+    without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var sensitivityJSON bool
+
+// sensitivityCmd represents the sensitivity command
+var sensitivityCmd = &cobra.Command{
+	Use:   "sensitivity <package.chlx> <tag>",
+	Short: "Find the values of a numeric answer where the tags change",
+	Long: `Load a checklist package, apply the answers and scan the values of the
+number or decimal feature with the tag, the other answers staying as given.
+
+Each range of values giving the same tags is printed with the tags set in
+it, among those changing from a range to another. With --json the
+breakpoints and the ranges are printed as a JSON object.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := loadProject(args[0])
+		if err != nil {
+			return err
+		}
+
+		sensitivity, err := project.Sensitivity(args[1])
+		if err != nil {
+			return err
+		}
+		if sensitivityJSON {
+			data, err := json.MarshalIndent(sensitivity, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return nil
+		}
+
+		for _, r := range sensitivity.Ranges {
+			set := make([]string, 0)
+			for t, ok := range r.Tags {
+				if ok {
+					set = append(set, t)
+				}
+			}
+			slices.Sort(set)
+			fmt.Fprintf(cmd.OutOrStdout(), "%s..%s\t%s\n", r.From, r.To, strings.Join(set, " "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sensitivityCmd)
+	addProjectFlags(sensitivityCmd)
+
+	sensitivityCmd.Flags().BoolVar(&sensitivityJSON, "json", false, "print the breakpoints and the ranges as JSON")
+}
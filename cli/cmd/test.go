@@ -0,0 +1,90 @@
+/*
+Copyright © 2023 Gianpaolo Terranova <g.terranova@sazalex.com>
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice,
+    this list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+ 3. Neither the name of the copyright holder nor the names of its contributors
+    may be used to endorse or promote products derived from this software
+    without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var testVerbose bool
+
+// testCmd represents the test command
+var testCmd = &cobra.Command{
+	Use:   "test <package.chlx>",
+	Short: "Run the package scenarios",
+	Long: `Run the scenarios stored in the tests directory of the package. Each
+scenario loads a set of answers and checks the resulting tags, values,
+disabled features and rendered templates.
+
+Snapshots of the rendered text can be produced with render --text.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := loadProject(args[0])
+		if err != nil {
+			return err
+		}
+
+		results, err := project.RunScenarios()
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range results {
+			switch {
+			case r.Err != nil:
+				failed++
+				fmt.Fprintf(cmd.OutOrStdout(), "ERROR %s (%s): %v\n", r.Scenario.Name, r.Scenario.Filename, r.Err)
+			case !r.Passed():
+				failed++
+				fmt.Fprintf(cmd.OutOrStdout(), "FAIL  %s (%s)\n", r.Scenario.Name, r.Scenario.Filename)
+				for _, f := range r.Failures {
+					fmt.Fprintf(cmd.OutOrStdout(), "      %s\n", f)
+				}
+			case testVerbose:
+				fmt.Fprintf(cmd.OutOrStdout(), "ok    %s\n", r.Scenario.Name)
+			}
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%d scenarios, %d failed\n", len(results), failed)
+		if failed > 0 {
+			return fmt.Errorf("%d scenarios failed", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+
+	testCmd.Flags().BoolVarP(&testVerbose, "verbose", "v", false, "also list the passing scenarios")
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2023 Gianpaolo Terranova <g.terranova@sazalex.com>
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice,
+    this list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+ 3. Neither the name of the copyright holder nor the names of its contributors
+    may be used to endorse or promote products derived from this software
+    without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"terra9.it/checkmate/core"
+)
+
+var validateJSON bool
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate <package.chlx>",
+	Short: "Evaluate the checklist and print the resulting tags",
+	Long: `Load a checklist package, apply the answers and evaluate every condition.
+
+Boolean tags are printed by name, the other tags as tag=value. With --json
+the tags are printed as a JSON object. The validation rules failed by the
+answers are printed on stderr and make the command fail.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := loadProject(args[0])
+		if err != nil {
+			return err
+		}
+
+		if validateJSON {
+			data, err := json.MarshalIndent(project.Tags, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return checkRules(cmd, project.Errors())
+		}
+
+		tags := make([]string, 0, len(project.Tags))
+		for t := range project.Tags {
+			tags = append(tags, t)
+		}
+		sort.Strings(tags)
+		for _, t := range tags {
+			switch v := project.Tags[t].(type) {
+			case bool:
+				fmt.Fprintln(cmd.OutOrStdout(), t)
+			default:
+				fmt.Fprintf(cmd.OutOrStdout(), "%s=%v\n", t, v)
+			}
+		}
+		return checkRules(cmd, project.Errors())
+	},
+}
+
+func checkRules(cmd *cobra.Command, errs core.ValidationErrors) error {
+	for _, e := range errs {
+		fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s (%s)\n", e.Tag, e.Message, e.Rule)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d validation rules failed", len(errs))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	addProjectFlags(validateCmd)
+
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "print the tags as JSON")
+}
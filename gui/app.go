@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"fyne.io/fyne/v2"
@@ -144,7 +147,7 @@ func (w *mainWindow) topBar(project *core.Project) fyne.CanvasObject {
 		}
 	})
 	if project != nil {
-		var resetAction, openAction, saveAction, saveAsAction *widget.ToolbarAction
+		var resetAction, openAction, saveAction, saveAsAction, undoAction, redoAction, historyAction, compareAction *widget.ToolbarAction
 		settingAction = widget.NewToolbarAction(theme.SettingsIcon(), func() {
 			//h := settingAction.ToolbarObject().MinSize().Height
 			//holder := aw.app.Driver().CanvasForObject(icon)
@@ -159,17 +162,15 @@ func (w *mainWindow) topBar(project *core.Project) fyne.CanvasObject {
 		})
 
 		resetAction = widget.NewToolbarAction(theme.FolderNewIcon(), func() {
-			project.ResetFeatures()
-			for _, f := range project.Features {
-				f.ApplyDefaults()
+			if err := project.ResetFeatures(); err != nil {
+				dialog.ShowError(err, w.window)
+				return
 			}
-			steps := make([]wizard.WizardStep, len(project.Features))
-			//steps[0] = NewProjectStep(project, w)
-			for i, feat := range project.Features {
-				steps[i] = NewMultiselectStep(project, w, feat)
+			if err := project.ApplyDefaults(); err != nil {
+				dialog.ShowError(err, w.window)
+				return
 			}
-			w.wizard.Steps = steps
-			w.Update(project)
+			w.reloadSteps(project)
 		})
 
 		openAction = widget.NewToolbarAction(theme.FolderOpenIcon(), func() {
@@ -224,22 +225,27 @@ func (w *mainWindow) topBar(project *core.Project) fyne.CanvasObject {
 			outputOptions := make([]*fyne.MenuItem, 0)
 			outputOptions = append(outputOptions, menuItem1)
 			outputOptions = append(outputOptions, fyne.NewMenuItemSeparator())
-			for _, t := range project.TemplateDefs {
+			for _, t := range project.Templates() {
 				template_def := t
 				m := fyne.NewMenuItem(t.Name, func() {
-					output, err := project.Render(template_def)
+					report, err := project.Render(context.Background(), template_def)
 					if err != nil {
 						dialog.ShowError(err, w.window)
 						return
 					}
-					if template_def.Format != "docx" {
-						details := widget.NewRichTextFromMarkdown(output)
+					if !template_def.IsDocument() {
+						details := widget.NewRichTextFromMarkdown(string(report.Data))
 						details.Wrapping = fyne.TextWrapWord
 						d := dialog.NewCustom(t.Name, "Chiudi", details, w.window)
 						//d.Resize(w.window.Canvas().Size())
 						d.Resize(w.window.Canvas().Size().Subtract(fyne.NewDelta(50, 50)))
 						d.Show()
 					} else {
+						output, err := saveReport(report)
+						if err != nil {
+							dialog.ShowError(err, w.window)
+							return
+						}
 						dialog.ShowConfirm("Report", "Report generato come "+output+". Vuoi aprirlo?", func(b bool) {
 							if b {
 								open.Start(output)
@@ -261,6 +267,40 @@ func (w *mainWindow) topBar(project *core.Project) fyne.CanvasObject {
 			}
 		})
 
+		undoAction = widget.NewToolbarAction(theme.ContentUndoIcon(), func() {
+			w.undo(project)
+		})
+		redoAction = widget.NewToolbarAction(theme.ContentRedoIcon(), func() {
+			w.redo(project)
+		})
+
+		historyAction = widget.NewToolbarAction(theme.HistoryIcon(), func() {
+			tabs := container.NewAppTabs(
+				container.NewTabItem("Modifiche", changesView(project)),
+				container.NewTabItem("Cronologia", historyView(project)),
+			)
+			d := dialog.NewCustom("Cronologia", "Chiudi", tabs, w.window)
+			d.Resize(w.window.Canvas().Size().Subtract(fyne.NewDelta(50, 50)))
+			d.Show()
+		})
+
+		compareAction = widget.NewToolbarAction(theme.ContentCopyIcon(), func() {
+			d := dialog.NewFileOpen(func(uc fyne.URIReadCloser, err error) {
+				if err == nil && uc != nil {
+					uc.Close()
+					diff, err := compareProject(project, uc.URI().Path())
+					if err != nil {
+						dialog.ShowError(err, w.window)
+						return
+					}
+					d := dialog.NewCustom("Confronto", "Chiudi", diffView(project, diff, uc.URI().Path()), w.window)
+					d.Resize(w.window.Canvas().Size().Subtract(fyne.NewDelta(50, 50)))
+					d.Show()
+				}
+			}, w.window)
+			d.Show()
+		})
+
 		toolbar = widget.NewToolbar(
 			widget.NewToolbarAction(theme.NavigateBackIcon(), func() {
 				w.LandingPage()
@@ -272,6 +312,11 @@ func (w *mainWindow) topBar(project *core.Project) fyne.CanvasObject {
 			saveAction,
 			saveAsAction,
 			widget.NewToolbarSeparator(),
+			undoAction,
+			redoAction,
+			historyAction,
+			compareAction,
+			widget.NewToolbarSeparator(),
 			settingAction,
 		)
 	} else {
@@ -290,6 +335,32 @@ func (w *mainWindow) topBar(project *core.Project) fyne.CanvasObject {
 	return container.NewBorder(toolbar, nil, nil, nil, nil)
 }
 
+// saveReport writes a document report to a temporary file, to be opened by
+// the application of its format.
+func saveReport(report *core.Report) (string, error) {
+	f, err := os.CreateTemp("", "checkmate-*"+filepath.Ext(report.Filename))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(report.Data); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// reloadSteps rebuilds the wizard steps, after the answers changed outside
+// of them.
+func (w *mainWindow) reloadSteps(project *core.Project) {
+	steps := make([]wizard.WizardStep, len(project.Features))
+	//steps[0] = NewProjectStep(project, w)
+	for i, feat := range project.Features {
+		steps[i] = NewMultiselectStep(project, w, feat)
+	}
+	w.wizard.Steps = steps
+	w.Update(project)
+}
+
 func (w *mainWindow) ChecklistContent(project *core.Project) fyne.CanvasObject {
 
 	wc := &wizardConfig{project: project}
@@ -304,6 +375,7 @@ func (w *mainWindow) ChecklistContent(project *core.Project) fyne.CanvasObject {
 	settings.RemoveThemeChangeListeners()
 	settings.AddThemeChangeListener(func() {
 		if w.wizard != nil {
+			w.setLanguage(project)
 			steps := make([]wizard.WizardStep, len(project.Features))
 			//steps[0] = NewProjectStep(project, w)
 			for i, feat := range project.Features {
@@ -333,13 +405,30 @@ func (w *mainWindow) ChecklistContent(project *core.Project) fyne.CanvasObject {
 	)
 }
 
+// setLanguage shows the project in the language chosen in the settings,
+// when the package provides it.
+func (w *mainWindow) setLanguage(project *core.Project) {
+	lang := settings.Language()
+	if !slices.Contains(project.Languages(), lang) {
+		return
+	}
+	if err := project.SetLanguage(lang); err != nil {
+		dialog.ShowError(err, w.window)
+	}
+}
+
 func (w *mainWindow) ChecklistPage(project *core.Project) {
+	w.setLanguage(project)
+	if project.User == "" {
+		project.User = desktopUser()
+	}
 	title := project.ProjectFile
 	if title == "" {
 		title = project.Name
 	}
 	w.window.SetTitle(title)
 	w.window.SetContent(container.New(layout.NewMaxLayout(), w.ChecklistContent(project)))
+	w.addUndoShortcuts(project)
 	w.window.SetCloseIntercept(func() {
 		w.LandingPage()
 	})
@@ -397,6 +486,7 @@ func (w *mainWindow) LandingPage() {
 
 	w.wizard = nil
 	w.window.SetTitle("SmartCheck")
+	w.removeUndoShortcuts()
 
 	settings.RemoveThemeChangeListeners()
 
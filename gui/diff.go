@@ -0,0 +1,74 @@
+package main
+
+import (
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"terra9.it/checkmate/core"
+)
+
+// compareProject compares the answers of the project with those of the
+// project saved in filename.
+func compareProject(project *core.Project, filename string) (*core.ProjectDiff, error) {
+	other, err := core.LoadProject(filename)
+	if err != nil {
+		return nil, err
+	}
+	return project.Diff(project.ExportData(), other.ExportData())
+}
+
+// diffView shows side by side what changes from the project to the one
+// saved in filename: answers, derived tags and sections of the templates.
+func diffView(project *core.Project, diff *core.ProjectDiff, filename string) fyne.CanvasObject {
+	bold := fyne.TextStyle{Bold: true}
+	box := container.NewVBox(container.NewGridWithColumns(3,
+		widget.NewLabel(""),
+		widget.NewLabelWithStyle("Questo progetto", fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle(filepath.Base(filename), fyne.TextAlignLeading, bold),
+	))
+	if diff.Empty() {
+		box.Add(widget.NewLabel("Nessuna differenza"))
+	}
+
+	for _, group := range []struct {
+		title   string
+		changes []core.TagChange
+	}{{"Risposte", diff.Inputs}, {"Tag derivati", diff.Derived}} {
+		if len(group.changes) == 0 {
+			continue
+		}
+		box.Add(widget.NewSeparator())
+		box.Add(widget.NewLabelWithStyle(group.title, fyne.TextAlignLeading, bold))
+		for _, c := range group.changes {
+			box.Add(container.NewGridWithColumns(3,
+				widget.NewLabel(tagTitle(project, c.Tag)),
+				widget.NewLabel(historyValue(c.Old)),
+				widget.NewLabel(historyValue(c.New)),
+			))
+		}
+	}
+
+	template := ""
+	for _, s := range diff.Sections {
+		if s.Template != template {
+			template = s.Template
+			box.Add(widget.NewSeparator())
+			box.Add(widget.NewLabelWithStyle(template, fyne.TextAlignLeading, bold))
+		}
+		box.Add(container.NewGridWithColumns(2, diffText(s.Old), diffText(s.New)))
+	}
+	return container.NewVScroll(box)
+}
+
+// diffText shows the text of a section, missing on one side when empty.
+func diffText(text string) fyne.CanvasObject {
+	if text == "" {
+		return widget.NewLabel("(sezione assente)")
+	}
+	rich := widget.NewRichTextFromMarkdown(text)
+	rich.Wrapping = fyne.TextWrapWord
+	return rich
+}
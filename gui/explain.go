@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"terra9.it/checkmate/core"
+	"terra9.it/checkmate/gui/widgets"
+)
+
+// explainIcon returns an icon showing, when tapped, why the feature with the
+// tag has its value and disabled state.
+func (step *MultiselectStep) explainIcon(tag string) *widgets.TappableIcon {
+	icon := widgets.NewTappableIcon(theme.QuestionIcon(), theme.IconInlineSize(), widgets.Tappable, nil, true)
+	step.setExplainTag(icon, tag)
+	return icon
+}
+
+// setExplainTag points the icon to another tag, for reused list rows.
+func (step *MultiselectStep) setExplainTag(icon *widgets.TappableIcon, tag string) {
+	icon.OnTapped = func() {
+		step.showExplanation(icon, tag)
+	}
+}
+
+// withExplainIcon puts the explain icon of the tag next to a form label.
+func (step *MultiselectStep) withExplainIcon(label fyne.CanvasObject, tag string) fyne.CanvasObject {
+	return container.NewBorder(nil, nil, nil, step.explainIcon(tag), label)
+}
+
+func (step *MultiselectStep) showExplanation(obj fyne.CanvasObject, tag string) {
+	e, err := step.project.Explain(tag)
+	if err != nil {
+		dialog.ShowError(err, step.w.window)
+		return
+	}
+	driver := fyne.CurrentApp().Driver()
+	text := widget.NewRichTextFromMarkdown(explanationMarkdown(e, ""))
+	popup := widget.NewPopUp(text, driver.CanvasForObject(obj))
+	popup.ShowAtPosition(driver.AbsolutePositionForObject(obj).AddXY(0, obj.MinSize().Height))
+}
+
+// explanationMarkdown renders the explanation as nested lists.
+func explanationMarkdown(e *core.Explanation, indent string) string {
+	var b strings.Builder
+	title := e.Title
+	if title == "" {
+		title = e.Tag
+	}
+	state := ""
+	if e.Disabled {
+		state = " (disabilitato)"
+	}
+	fmt.Fprintf(&b, "%s- **%s** = %s%s\n", indent, title, historyValue(e.Value), state)
+	for _, t := range []struct {
+		label string
+		trace *core.ExpressionTrace
+	}{{"valore da", e.Condition}, {"disabilitato se", e.DisabledOn}} {
+		if t.trace == nil {
+			continue
+		}
+		where := ""
+		if t.trace.Feature != e.Tag {
+			where = " in " + t.trace.Feature
+		}
+		fmt.Fprintf(&b, "%s  - %s `%s`%s → %s\n", indent, t.label, t.trace.Expression, where, historyValue(t.trace.Result))
+		for _, input := range t.trace.Inputs {
+			b.WriteString(explanationMarkdown(input, indent+"    "))
+		}
+	}
+	if e.Condition == nil && !e.Disabled {
+		fmt.Fprintf(&b, "%s  - risposta o valore predefinito\n", indent)
+	}
+	return b.String()
+}
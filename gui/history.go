@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os/user"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"terra9.it/checkmate/core"
+)
+
+const allAnswers = "Tutte le risposte"
+
+// desktopUser names the user of the computer in the audit log.
+func desktopUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	if u.Name != "" {
+		return u.Name
+	}
+	return u.Username
+}
+
+// historyView lists the changes of the answers, the newest first, only
+// those of the tag chosen.
+func historyView(project *core.Project) fyne.CanvasObject {
+	entries := project.AuditLog()
+	tags := make([]string, 0)
+	for _, e := range entries {
+		if !slices.Contains(tags, e.Tag) {
+			tags = append(tags, e.Tag)
+		}
+	}
+	slices.Sort(tags)
+
+	shown := entries
+	list := widget.NewList(
+		func() int {
+			return len(shown)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(historyLine(project, shown[len(shown)-1-i]))
+		},
+	)
+	tagSelect := widget.NewSelect(append([]string{allAnswers}, tags...), func(tag string) {
+		if tag == allAnswers {
+			shown = entries
+		} else {
+			shown = project.History(tag)
+		}
+		list.Refresh()
+	})
+	tagSelect.SetSelected(allAnswers)
+
+	return container.NewBorder(tagSelect, nil, nil, nil, list)
+}
+
+// changesView lists the changes that can be undone, the last one first,
+// each with the tags its conditions changed.
+func changesView(project *core.Project) fyne.CanvasObject {
+	changes := project.Changes()
+	box := container.NewVBox()
+	if len(changes) == 0 {
+		box.Add(widget.NewLabel("Nessuna modifica da annullare"))
+	}
+	for _, c := range changes {
+		box.Add(widget.NewLabelWithStyle(changeLine(project, c.TagChange), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		for _, d := range c.Derived {
+			box.Add(widget.NewLabel("    ↳ " + changeLine(project, d)))
+		}
+	}
+	return container.NewVScroll(box)
+}
+
+func changeLine(project *core.Project, c core.TagChange) string {
+	return fmt.Sprintf("%s: %s → %s", tagTitle(project, c.Tag), historyValue(c.Old), historyValue(c.New))
+}
+
+func tagTitle(project *core.Project, tag string) string {
+	if f := project.GetFeature(tag); f != nil && f.GetTitle() != "" {
+		return f.GetTitle()
+	}
+	return tag
+}
+
+func historyLine(project *core.Project, e core.AuditEntry) string {
+	name := tagTitle(project, e.Tag)
+	who := e.User
+	if who == "" {
+		who = "-"
+	}
+	return fmt.Sprintf("%s  %s  %s: %s → %s", e.Time.Local().Format("02/01/2006 15:04:05"), who, name,
+		historyValue(e.Old), historyValue(e.New))
+}
+
+func historyValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "(vuoto)"
+	case bool:
+		if v {
+			return "sì"
+		}
+		return "no"
+	case string:
+		if v == "" {
+			return "(vuoto)"
+		}
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprint(value)
+}
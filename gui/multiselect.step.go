@@ -7,6 +7,7 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
@@ -78,11 +79,13 @@ func (step *MultiselectStep) CreateChecklistContainer() *widgets.MultiSelectList
 	}
 	return widgets.NewMultiSelectList(options,
 		func(item *widgets.MultiSelectListItem) fyne.CanvasObject {
-			return widget.NewLabel("")
+			return container.NewBorder(nil, nil, nil, step.explainIcon(""), widget.NewLabel(""))
 		},
 		func(item *widgets.MultiSelectListItem, cnvObj fyne.CanvasObject) {
-			label := cnvObj.(*widget.Label)
-			label.SetText(item.Value.(core.Feature).GetTitle())
+			row := cnvObj.(*fyne.Container)
+			feature := item.Value.(core.Feature)
+			row.Objects[0].(*widget.Label).SetText(feature.GetTitle())
+			step.setExplainTag(row.Objects[1].(*widgets.TappableIcon), feature.GetTag())
 		},
 		func(item *widgets.MultiSelectListItem) {
 			condition := item.Value.(core.Feature)
@@ -94,8 +97,16 @@ func (step *MultiselectStep) CreateChecklistContainer() *widgets.MultiSelectList
 }
 
 func (step *MultiselectStep) CreateFormContainer() fyne.CanvasObject {
+	objects := step.createFormObjects(step.feature.GetChildren(), "")
+	return container.New(layout.NewFormLayout(), objects...)
+}
+
+// createFormObjects returns a label and a control for each feature. The
+// prefix is prepended to the feature tags, so that the fields of an array
+// item are set through the array path.
+func (step *MultiselectStep) createFormObjects(children []core.Feature, prefix string) []fyne.CanvasObject {
 	objects := make([]fyne.CanvasObject, 0)
-	for _, child := range step.feature.GetChildren() {
+	for _, child := range children {
 		switch t := child.(type) {
 		case *core.String:
 
@@ -105,7 +116,7 @@ func (step *MultiselectStep) CreateFormContainer() fyne.CanvasObject {
 				control.SetText(inputFeature.GetValue().(string))
 			}
 			control.OnChanged = func(s string) {
-				step.project.SetFeature(inputFeature.GetTag(), s)
+				step.project.SetFeature(prefix+inputFeature.GetTag(), s)
 				step.w.Update(step.project)
 			}
 			controlLabel := widget.NewLabel(inputFeature.GetTitle())
@@ -118,10 +129,68 @@ func (step *MultiselectStep) CreateFormContainer() fyne.CanvasObject {
 		case *core.Number:
 			inputFeature := t
 			control := xwidget.NewNumericalEntry()
-			control.SetText(fmt.Sprintf("%v", inputFeature.Value))
+			if value := inputFeature.GetValue(); value != nil {
+				control.SetText(fmt.Sprint(value))
+			}
+			control.OnChanged = func(s string) {
+				// an empty entry clears the answer
+				var value any
+				if s != "" {
+					value, _ = strconv.ParseInt(s, 10, 64)
+				}
+				step.project.SetFeature(prefix+inputFeature.Tag, value)
+				step.w.Update(step.project)
+			}
+			controlLabel := widget.NewLabel(inputFeature.Title)
+			controlLabel.TextStyle = fyne.TextStyle{
+				Bold: true,
+			}
+			controlLabel.Alignment = fyne.TextAlignLeading
+			objects = append(objects, controlLabel, control)
+		case *core.Decimal:
+			inputFeature := t
+			control := widget.NewEntry()
+			if value := inputFeature.GetValue(); value != nil {
+				control.SetText(fmt.Sprint(value))
+			}
+			control.Validator = func(s string) error {
+				_, err := core.ParseDecimal(s)
+				return err
+			}
 			control.OnChanged = func(s string) {
-				value, _ := strconv.ParseInt(s, 10, 64)
-				step.project.SetFeature(inputFeature.Tag, value)
+				if control.Validate() != nil {
+					return
+				}
+				step.project.SetFeature(prefix+inputFeature.Tag, s)
+				step.w.Update(step.project)
+			}
+			title := inputFeature.Title
+			if inputFeature.Unit != "" {
+				title = fmt.Sprintf("%s (%s)", title, inputFeature.Unit)
+			}
+			controlLabel := widget.NewLabel(title)
+			controlLabel.TextStyle = fyne.TextStyle{
+				Bold: true,
+			}
+			controlLabel.Alignment = fyne.TextAlignLeading
+			objects = append(objects, controlLabel, control)
+		case *core.Date:
+			inputFeature := t
+			control := widget.NewEntry()
+			control.SetPlaceHolder("AAAA-MM-GG")
+			control.SetText(inputFeature.Value)
+			control.Validator = func(s string) error {
+				if s == "" {
+					return nil
+				}
+				_, err := core.ParseDay(s)
+				return err
+			}
+			control.OnChanged = func(s string) {
+				if control.Validate() != nil {
+					return
+				}
+				step.project.SetFeature(prefix+inputFeature.Tag, s)
 				step.w.Update(step.project)
 			}
 			controlLabel := widget.NewLabel(inputFeature.Title)
@@ -131,6 +200,10 @@ func (step *MultiselectStep) CreateFormContainer() fyne.CanvasObject {
 			controlLabel.Alignment = fyne.TextAlignLeading
 			objects = append(objects, controlLabel, control)
 		case *core.Select:
+			if t.Multiple {
+				objects = append(objects, step.createCheckGroup(t, prefix)...)
+				break
+			}
 			selectFeature := t
 			options := make([]any, 0)
 			for _, option := range selectFeature.GetChildren() {
@@ -154,12 +227,16 @@ func (step *MultiselectStep) CreateFormContainer() fyne.CanvasObject {
 			)
 
 			control.OnChanged = func(item any) {
-				for _, c := range options {
-					c.(core.Feature).SetValue(false)
-					step.project.SetFeature(c.(core.Feature).GetTag(), false)
-				}
-				feature := item.(core.Feature)
-				step.project.SetFeature(feature.GetTag(), true)
+				// one change to undo
+				step.project.Batch(func() error {
+					for _, c := range options {
+						if err := step.project.SetFeature(prefix+c.(core.Feature).GetTag(), false); err != nil {
+							return err
+						}
+					}
+					feature := item.(core.Feature)
+					return step.project.SetFeature(prefix+feature.GetTag(), true)
+				})
 				//fmt.Println(step.project.Tags, control.SelectedItem())
 				step.w.Update(step.project)
 			}
@@ -169,12 +246,132 @@ func (step *MultiselectStep) CreateFormContainer() fyne.CanvasObject {
 			}
 			controlLabel.Alignment = fyne.TextAlignLeading
 
+			objects = append(objects, controlLabel, control)
+		case *core.Array:
+			arrayFeature := t
+			control := container.NewVBox()
+			step.fillArrayContainer(control, arrayFeature, prefix)
+			controlLabel := widget.NewLabel(arrayFeature.Title)
+			controlLabel.TextStyle = fyne.TextStyle{
+				Bold: true,
+			}
+			controlLabel.Alignment = fyne.TextAlignLeading
 			objects = append(objects, controlLabel, control)
 		default:
 			panic("Unknown type")
 		}
+		// the fields of array items have no explanation, their tags being
+		// paths
+		if prefix == "" {
+			label := len(objects) - 2
+			objects[label] = step.withExplainIcon(objects[label], child.GetTag())
+		}
 	}
-	return container.New(layout.NewFormLayout(), objects...)
+	return objects
+}
+
+// createCheckGroup returns the label and the check group of a multiple
+// select.
+func (step *MultiselectStep) createCheckGroup(selectFeature *core.Select, prefix string) []fyne.CanvasObject {
+	labels := make([]string, 0)
+	tags := make(map[string]string)
+	for _, option := range selectFeature.Enum {
+		if !option.IsDisabled() {
+			labels = append(labels, option.Title)
+			tags[option.Title] = option.Tag
+		}
+	}
+	selected := func() []string {
+		s := make([]string, 0)
+		for _, option := range selectFeature.Enum {
+			if option.Value {
+				s = append(s, option.Title)
+			}
+		}
+		return s
+	}
+
+	control := widget.NewCheckGroup(labels, nil)
+	control.SetSelected(selected())
+	control.OnChanged = func(s []string) {
+		value := make([]string, 0, len(s))
+		for _, label := range s {
+			value = append(value, tags[label])
+		}
+		if err := step.project.SetFeature(prefix+selectFeature.Tag, value); err != nil {
+			dialog.ShowError(err, step.w.window)
+			// back to the last accepted choices
+			control.SetSelected(selected())
+			return
+		}
+		step.w.Update(step.project)
+	}
+	controlLabel := widget.NewLabel(selectFeature.Title)
+	controlLabel.TextStyle = fyne.TextStyle{
+		Bold: true,
+	}
+	controlLabel.Alignment = fyne.TextAlignLeading
+	return []fyne.CanvasObject{controlLabel, control}
+}
+
+// fillArrayContainer (re)builds the items of an array, each one with its
+// own form and the buttons to add, remove and reorder items.
+func (step *MultiselectStep) fillArrayContainer(box *fyne.Container, array *core.Array, prefix string) {
+	tag := prefix + array.Tag
+	changed := func(err error) {
+		if err != nil {
+			dialog.ShowError(err, step.w.window)
+			return
+		}
+		step.fillArrayContainer(box, array, prefix)
+		step.w.Update(step.project)
+	}
+
+	box.RemoveAll()
+	for i := 0; i < array.Len(); i++ {
+		index := i
+		title := fmt.Sprintf("%s %d", array.ItemTitle, index+1)
+		if array.ItemTitle == "" {
+			title = fmt.Sprintf("#%d", index+1)
+		}
+		itemLabel := widget.NewLabel(title)
+		itemLabel.TextStyle = fyne.TextStyle{
+			Italic: true,
+		}
+		removeButton := widget.NewButtonWithIcon("Rimuovi", theme.DeleteIcon(), func() {
+			changed(step.project.RemoveArrayItem(tag, index))
+		})
+		upButton := widget.NewButtonWithIcon("Su", theme.MoveUpIcon(), func() {
+			changed(step.project.MoveArrayItem(tag, index, index-1))
+		})
+		downButton := widget.NewButtonWithIcon("Giù", theme.MoveDownIcon(), func() {
+			changed(step.project.MoveArrayItem(tag, index, index+1))
+		})
+		if index == 0 {
+			upButton.Disable()
+		}
+		if index == array.Len()-1 {
+			downButton.Disable()
+		}
+		if array.MinItems > 0 && array.Len() <= array.MinItems {
+			removeButton.Disable()
+		}
+
+		itemPrefix := fmt.Sprintf("%s%s.%d.", prefix, array.Tag, index)
+		objects := step.createFormObjects(array.Item(index).GetChildren(), itemPrefix)
+		box.Add(container.NewBorder(nil, nil, itemLabel, container.NewHBox(upButton, downButton, removeButton)))
+		box.Add(container.New(layout.NewFormLayout(), objects...))
+		box.Add(widget.NewSeparator())
+	}
+
+	addButton := widget.NewButtonWithIcon("Aggiungi", theme.ContentAddIcon(), func() {
+		changed(step.project.AddArrayItem(tag))
+	})
+	if array.MaxItems > 0 && array.Len() >= array.MaxItems {
+		addButton.Disable()
+	}
+	box.Add(container.NewHBox(addButton))
+	box.Refresh()
 }
 
 func (step *MultiselectStep) Disabled() bool {
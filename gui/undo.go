@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+
+	"terra9.it/checkmate/core"
+)
+
+var (
+	undoShortcut    = &desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: fyne.KeyModifierShortcutDefault}
+	redoShortcut    = &desktop.CustomShortcut{KeyName: fyne.KeyY, Modifier: fyne.KeyModifierShortcutDefault}
+	redoShortcutAlt = &desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift}
+)
+
+func (w *mainWindow) addUndoShortcuts(project *core.Project) {
+	canvas := w.window.Canvas()
+	canvas.AddShortcut(undoShortcut, func(fyne.Shortcut) {
+		w.undo(project)
+	})
+	for _, s := range []*desktop.CustomShortcut{redoShortcut, redoShortcutAlt} {
+		canvas.AddShortcut(s, func(fyne.Shortcut) {
+			w.redo(project)
+		})
+	}
+}
+
+func (w *mainWindow) removeUndoShortcuts() {
+	for _, s := range []*desktop.CustomShortcut{undoShortcut, redoShortcut, redoShortcutAlt} {
+		w.window.Canvas().RemoveShortcut(s)
+	}
+}
+
+func (w *mainWindow) undo(project *core.Project) {
+	if _, err := project.Undo(); err != nil {
+		if !errors.Is(err, core.ErrNothingToUndo) {
+			dialog.ShowError(err, w.window)
+		}
+		return
+	}
+	w.reloadSteps(project)
+}
+
+func (w *mainWindow) redo(project *core.Project) {
+	if _, err := project.Redo(); err != nil {
+		if !errors.Is(err, core.ErrNothingToRedo) {
+			dialog.ShowError(err, w.window)
+		}
+		return
+	}
+	w.reloadSteps(project)
+}
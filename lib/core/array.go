@@ -0,0 +1,438 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gterranova/go-bexpr"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ARRAY_COUNT = "count"
+	ARRAY_ITEMS = "items"
+	ARRAY_ANY   = "any"
+	ARRAY_ALL   = "all"
+	ARRAY_SUM   = "sum"
+)
+
+// Array is a repeating group of Checkforms sharing the schema in Items.
+//
+// Each item is evaluated in its own scope, where item tags are visible
+// next to the project tags and shadow them. In Project.Tags the array is
+// exposed as a map holding the number of items, the tags of every item and
+// the aggregates of the item tags:
+//
+//	tags.parcels.count            number of items
+//	tags.parcels.items            tags of every item
+//	tags.parcels.any.protected    true if any item sets the tag
+//	tags.parcels.all.protected    true if all the items set the tag
+//	tags.parcels.sum.area         sum of the item numbers
+//
+// Expressions may use the count, any, all and sum helpers for the same.
+type Array struct {
+	Type       string          `json:"type"`
+	Title      string          `json:"title"`
+	Disabled   bool            `json:"disabled,omitempty"`
+	Tag        string          `json:"tag,omitempty"`
+	DisabledOn string          `json:"disabled_on,omitempty"`
+	InfoUrl    string          `json:"info_url,omitempty"`
+	ItemTitle  string          `json:"item_title,omitempty"`
+	MinItems   int             `json:"min_items,omitempty"`
+	MaxItems   int             `json:"max_items,omitempty"`
+	Items      json.RawMessage `json:"items"`
+
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
+
+	schema *Checkform
+	items  []*arrayItem
+}
+
+type arrayItem struct {
+	form    *Checkform
+	project *Project
+}
+
+func (feature *Array) Validate(tag string, i any) (changed bool, err error) {
+	var itemsChanged bool
+
+	if changed, err = feature.EvaluateDisabledOn(i); err != nil {
+		return
+	}
+	itemsChanged, err = feature.EvaluateCondition(i)
+	return changed || itemsChanged, err
+}
+
+// EvaluateCondition evaluates the expressions of every item.
+func (feature *Array) EvaluateCondition(i any) (changed bool, err error) {
+	var outer map[string]any
+	if p, ok := i.(*Project); ok {
+		outer = p.Tags
+	}
+	for n, item := range feature.items {
+		item.project.outer = outer
+		itemChanged, item_err := item.project.Validate("")
+		if item_err != nil {
+			return changed, fmt.Errorf("item %d of %q: %v", n+1, feature.Tag, item_err)
+		}
+		changed = changed || itemChanged
+	}
+	return
+}
+
+func (feature *Array) EvaluateDisabledOn(i any) (changed bool, err error) {
+	if len(feature.DisabledOn) > 0 {
+		result, err := feature.disabledEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
+		}
+		b, _ := bexpr.CoerceBool(result)
+		changed = feature.Disabled != b
+		feature.Disabled = b
+	}
+	return
+}
+
+// ScopeTags implements FeatureWithScope, returning the project tags read by
+// the item expressions.
+func (feature *Array) ScopeTags() ([]string, error) {
+	schema, err := feature.Schema()
+	if err != nil {
+		return nil, err
+	}
+	local := featureTags(schema)
+	reads := make([]string, 0)
+	add := func(tags []string) {
+		for _, t := range tags {
+			if IndexOf(local, t) == -1 && IndexOf(reads, t) == -1 {
+				reads = append(reads, t)
+			}
+		}
+	}
+
+	var walk func(f Feature) error
+	walk = func(f Feature) error {
+		if fs, ok := f.(FeatureWithScope); ok {
+			tags, err := fs.ScopeTags()
+			if err != nil {
+				return err
+			}
+			add(tags)
+		}
+		if fe, ok := f.(FeatureWithExpressions); ok {
+			for _, expression := range []string{fe.GetCondition(), fe.GetDisabledOn()} {
+				if expression == "" {
+					continue
+				}
+				tags, err := ExpressionTags(expression)
+				if err != nil {
+					return fmt.Errorf("failed to parse expression %q: %v", expression, err)
+				}
+				add(tags)
+			}
+		}
+		for _, child := range f.GetChildren() {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(schema); err != nil {
+		return nil, err
+	}
+	return reads, nil
+}
+
+// Schema returns the item Checkform, without values.
+func (feature *Array) Schema() (*Checkform, error) {
+	if feature.schema == nil {
+		form, err := feature.parseSchema()
+		if err != nil {
+			return nil, err
+		}
+		feature.schema = form
+	}
+	return feature.schema, nil
+}
+
+func (feature *Array) parseSchema() (*Checkform, error) {
+	if len(feature.Items) == 0 {
+		return nil, fmt.Errorf("array %q has no items schema", feature.Tag)
+	}
+	form := &Checkform{}
+	if err := json.Unmarshal(feature.Items, form); err != nil {
+		return nil, fmt.Errorf("invalid items schema for %q: %v", feature.Tag, err)
+	}
+	if form.Type == "" {
+		form.Type = "checkform"
+	}
+	return form, nil
+}
+
+func (feature *Array) newItem() (*arrayItem, error) {
+	form, err := feature.parseSchema()
+	if err != nil {
+		return nil, err
+	}
+	if err := form.ApplyDefaults(); err != nil {
+		return nil, err
+	}
+	return &arrayItem{
+		form: form,
+		project: &Project{
+			Tags:     make(map[string]any),
+			Features: []Feature{form},
+		},
+	}, nil
+}
+
+func (feature *Array) ApplyDefaults() error {
+	// apply defaults
+	if feature.DisabledOn != "" {
+		eval, err := NewEvaluator(feature.DisabledOn)
+		if err != nil {
+			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
+		} else {
+			feature.disabledEvaluator = eval
+		}
+	}
+
+	if _, err := feature.Schema(); err != nil {
+		return err
+	}
+	return feature.SetValue(nil)
+}
+
+// Set accepts the array tag, to replace all the items, or a path as
+// `<tag>.<index>.<item tag>` to set a tag of a single item.
+func (feature *Array) Set(tag string, value any) error {
+	if feature.Tag == tag {
+		return feature.SetValue(value)
+	}
+	rest, found := strings.CutPrefix(tag, feature.Tag+".")
+	if feature.Tag == "" || !found {
+		return nil
+	}
+	index, itemTag, _ := strings.Cut(rest, ".")
+	n, err := strconv.Atoi(index)
+	if err != nil {
+		return nil
+	}
+	if n < 0 || n >= len(feature.items) {
+		return fmt.Errorf("no item %d in %q", n, feature.Tag)
+	}
+	return feature.items[n].form.Set(itemTag, value)
+}
+
+func (feature *Array) ApplicableFeatures() []Feature {
+	f := make([]Feature, 0)
+	if !feature.Disabled {
+		f = append(f, feature)
+	}
+	return f
+}
+
+// GetChildren returns no features: items are evaluated in their own scope
+// and their tags never reach Project.Tags directly.
+func (feature *Array) GetChildren() []Feature {
+	return []Feature{}
+}
+
+func (feature *Array) GetValue() any {
+	values := make([]any, 0, len(feature.items))
+	for _, item := range feature.items {
+		values = append(values, item.form.GetValue())
+	}
+	return values
+}
+
+// GetTagValue implements FeatureWithTagValue.
+func (feature *Array) GetTagValue() any {
+	items := make([]any, 0, len(feature.items))
+	for _, item := range feature.items {
+		tags := make(map[string]any)
+		for _, t := range item.form.ApplicableFeatures() {
+			if v := featureTagValue(t); v != nil {
+				tags[t.GetTag()] = v
+			}
+		}
+		items = append(items, tags)
+	}
+
+	anyTags := make(map[string]any)
+	allTags := make(map[string]any)
+	sumTags := make(map[string]any)
+	if schema, err := feature.Schema(); err == nil {
+		var walk func(f Feature)
+		walk = func(f Feature) {
+			tag := f.GetTag()
+			switch TagType(f) {
+			case "bool":
+				anyTags[tag], allTags[tag] = false, true
+				for _, tags := range items {
+					b := tags.(map[string]any)[tag] == true
+					anyTags[tag] = anyTags[tag].(bool) || b
+					allTags[tag] = allTags[tag].(bool) && b
+				}
+			case "number":
+				sum := decimal.Zero
+				places := int32(0)
+				for _, tags := range items {
+					if d, err := ParseDecimal(tags.(map[string]any)[tag]); err == nil {
+						sum = sum.Add(d)
+						places = max(places, -d.Exponent())
+					}
+				}
+				sumTags[tag] = json.Number(sum.StringFixed(places))
+			}
+			for _, child := range f.GetChildren() {
+				walk(child)
+			}
+		}
+		walk(schema)
+	}
+
+	return map[string]any{
+		ARRAY_COUNT: int64(len(items)),
+		ARRAY_ITEMS: items,
+		ARRAY_ANY:   anyTags,
+		ARRAY_ALL:   allTags,
+		ARRAY_SUM:   sumTags,
+	}
+}
+
+// SetValue replaces the items with the given list of item values; nil
+// resets the array to MinItems empty items.
+func (feature *Array) SetValue(value any) error {
+	var values []any
+
+	switch t := value.(type) {
+	case nil:
+		values = make([]any, 0)
+	case []any:
+		values = t
+	case []map[string]any:
+		values = make([]any, len(t))
+		for k, v := range t {
+			values[k] = v
+		}
+	default:
+		return fmt.Errorf("unknown type %T for %v", t, t)
+	}
+	if feature.MaxItems > 0 && len(values) > feature.MaxItems {
+		return fmt.Errorf("%q allows at most %d items", feature.Tag, feature.MaxItems)
+	}
+
+	items := make([]*arrayItem, 0, max(len(values), feature.MinItems))
+	for n, v := range values {
+		item, err := feature.newItem()
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			if err := item.form.SetValue(v); err != nil {
+				return fmt.Errorf("item %d of %q: %v", n+1, feature.Tag, err)
+			}
+		}
+		items = append(items, item)
+	}
+	for len(items) < feature.MinItems {
+		item, err := feature.newItem()
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	feature.items = items
+	return nil
+}
+
+// Len returns the number of items.
+func (feature *Array) Len() int {
+	return len(feature.items)
+}
+
+// Item returns the Checkform of the item at index.
+func (feature *Array) Item(index int) *Checkform {
+	if index < 0 || index >= len(feature.items) {
+		return nil
+	}
+	return feature.items[index].form
+}
+
+// AddItem appends an empty item and returns it.
+func (feature *Array) AddItem() (*Checkform, error) {
+	if feature.MaxItems > 0 && len(feature.items) >= feature.MaxItems {
+		return nil, fmt.Errorf("%q allows at most %d items", feature.Tag, feature.MaxItems)
+	}
+	item, err := feature.newItem()
+	if err != nil {
+		return nil, err
+	}
+	feature.items = append(feature.items, item)
+	return item.form, nil
+}
+
+// RemoveItem removes the item at index.
+func (feature *Array) RemoveItem(index int) error {
+	if index < 0 || index >= len(feature.items) {
+		return fmt.Errorf("no item %d in %q", index, feature.Tag)
+	}
+	if len(feature.items) <= feature.MinItems {
+		return fmt.Errorf("%q requires at least %d items", feature.Tag, feature.MinItems)
+	}
+	feature.items = append(feature.items[:index], feature.items[index+1:]...)
+	return nil
+}
+
+// MoveItem moves the item at from to the position to.
+func (feature *Array) MoveItem(from, to int) error {
+	if from < 0 || from >= len(feature.items) {
+		return fmt.Errorf("no item %d in %q", from, feature.Tag)
+	}
+	if to < 0 || to >= len(feature.items) {
+		return fmt.Errorf("no item %d in %q", to, feature.Tag)
+	}
+	item := feature.items[from]
+	feature.items = append(feature.items[:from], feature.items[from+1:]...)
+	feature.items = append(feature.items[:to], append([]*arrayItem{item}, feature.items[to:]...)...)
+	return nil
+}
+
+func (feature *Array) GetTag() string {
+	return feature.Tag
+}
+func (feature *Array) SetTag(tag string) {
+	feature.Tag = tag
+}
+func (feature *Array) IsDisabled() bool {
+	return feature.Disabled
+}
+
+func (feature *Array) GetType() string {
+	return feature.Type
+}
+func (feature *Array) GetTitle() string {
+	return feature.Title
+}
+
+// InfoUrl implements FeatureWithInfoUrl.
+func (feature *Array) GetInfoUrl() string {
+	return feature.InfoUrl
+}
+
+func (feature *Array) GetCondition() string {
+	return ""
+}
+
+func (feature *Array) GetDisabledOn() string {
+	return feature.DisabledOn
+}
+
+func (p *Array) MarshalJSON() ([]byte, error) {
+	marshaller := &ArrayFormly{p}
+	return marshaller.MarshalJSON()
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"encoding/json"
+)
+
+type ArrayFormly struct {
+	*Array
+}
+
+func (feature *ArrayFormly) MarshalJSON() ([]byte, error) {
+	type jsonFeature struct {
+		Type     string     `json:"type"`
+		Title    string     `json:"title"`
+		Disabled bool       `json:"disabled,omitempty"`
+		MinItems int        `json:"minItems,omitempty"`
+		MaxItems int        `json:"maxItems,omitempty"`
+		Items    *Checkform `json:"items"`
+		Value    any        `json:"value"`
+		Widget   struct {
+			FormlyConfig map[string]any `json:"formlyConfig,omitempty"`
+		} `json:"widget,omitempty"`
+	}
+
+	schema, err := feature.Schema()
+	if err != nil {
+		return nil, err
+	}
+	foo := jsonFeature{
+		Type:     "array",
+		Title:    feature.Title,
+		Disabled: feature.Disabled,
+		MinItems: feature.MinItems,
+		MaxItems: feature.MaxItems,
+		Items:    schema,
+		Value:    feature.GetValue(),
+	}
+
+	props := map[string]any{}
+	if feature.ItemTitle != "" {
+		props["item_title"] = feature.ItemTitle
+	}
+	if feature.InfoUrl != "" {
+		props["info_url"] = feature.InfoUrl
+	}
+	foo.Widget.FormlyConfig = map[string]any{
+		"type":  "array",
+		"props": props,
+	}
+
+	valueBytes, err := json.Marshal(foo)
+	if err != nil {
+		return nil, err
+	}
+
+	return valueBytes, nil
+}
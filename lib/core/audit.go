@@ -0,0 +1,118 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AUDIT_FILE keeps the audit log in the package, next to data.json.
+const AUDIT_FILE = "audit.json"
+
+// AuditEntry records the change of an answer: who gave it, when, and the
+// values before and after.
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	User string    `json:"user,omitempty"`
+	Tag  string    `json:"tag"`
+	Old  any       `json:"old"`
+	New  any       `json:"new"`
+}
+
+// AuditLog returns the changes of the answers, the oldest first. The log
+// only grows: SetFeature and LoadProjectData append to it, and it is saved
+// in the package with the answers.
+func (p *Project) AuditLog() []AuditEntry {
+	return slices.Clone(p.audit)
+}
+
+// History returns the changes of the answer with the tag, the oldest first.
+func (p *Project) History(tag string) []AuditEntry {
+	entries := make([]AuditEntry, 0)
+	for _, e := range p.audit {
+		if e.Tag == tag {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// answer returns the value of a tag as accepted by SetFeature, array items
+// included, nil when there is none.
+func (p *Project) answer(tag string) any {
+	if f := p.GetFeature(tag); f != nil {
+		return f.GetValue()
+	}
+	parts := strings.Split(tag, ".")
+	f := p.GetFeature(parts[0])
+	if f == nil {
+		return nil
+	}
+	value := f.GetValue()
+	for _, part := range parts[1:] {
+		switch v := value.(type) {
+		case []any:
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 || n >= len(v) {
+				return nil
+			}
+			value = v[n]
+		case map[string]any:
+			value = v[part]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// answers returns the values of the features without children, unchecked
+// and disabled ones included.
+func (p *Project) answers() map[string]any {
+	values := make(map[string]any)
+	var walk func(features []Feature)
+	walk = func(features []Feature) {
+		for _, f := range features {
+			if children := f.GetChildren(); len(children) > 0 {
+				walk(children)
+			} else if tag := f.GetTag(); tag != "" {
+				values[tag] = f.GetValue()
+			}
+		}
+	}
+	walk(p.Features)
+	return values
+}
+
+func (p *Project) record(tag string, old, value any) {
+	if p.loading || reflect.DeepEqual(old, value) {
+		return
+	}
+	p.audit = append(p.audit, AuditEntry{
+		Time: time.Now().UTC(),
+		User: p.User,
+		Tag:  tag,
+		Old:  old,
+		New:  value,
+	})
+}
+
+// recordAnswers records the answers changed since before, by tag.
+func (p *Project) recordAnswers(before map[string]any) {
+	after := p.answers()
+	tags := make([]string, 0, len(after))
+	for tag := range after {
+		tags = append(tags, tag)
+	}
+	for tag := range before {
+		if _, ok := after[tag]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	for _, tag := range tags {
+		p.record(tag, before[tag], after[tag])
+	}
+}
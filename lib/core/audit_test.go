@@ -0,0 +1,36 @@
+package core
+
+import (
+	"testing"
+)
+
+const auditConfig = `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+	"a": {"type": "checkbox", "tag": "a", "title": "A"},
+	"b": {"type": "checkbox", "tag": "b", "title": "B"}}}]}`
+
+func TestResetFeaturesKeepsTheAuditLog(t *testing.T) {
+	p := newTestProject(t, auditConfig, nil)
+	mustSet(t, p, "a", true)
+	if err := p.ResetFeatures(); err != nil {
+		t.Fatal(err)
+	}
+	log := p.AuditLog()
+	if len(log) != 2 {
+		t.Fatalf("got %d audit entries, want the answer and its reset: %v", len(log), log)
+	}
+	if e := log[1]; e.Tag != "a" || e.Old != true || e.New != false {
+		t.Errorf("unexpected reset entry %+v", e)
+	}
+}
+
+func TestLoadProjectDataRecordsTheChangesOnly(t *testing.T) {
+	p := newTestProject(t, auditConfig, nil)
+	mustSet(t, p, "a", true)
+	if err := p.LoadProjectData(ProjectExport{Values: map[string]any{"a": true, "b": true}}); err != nil {
+		t.Fatal(err)
+	}
+	log := p.AuditLog()
+	if len(log) != 2 || log[1].Tag != "b" || log[1].New != true {
+		t.Errorf("got %v, want the answer of a and the change of b", log)
+	}
+}
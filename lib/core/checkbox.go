@@ -18,11 +18,21 @@ type Checkbox struct {
 	DisabledOn string `json:"disabled_on,omitempty"`
 	InfoUrl    string `json:"info_url,omitempty"`
 
-	valueEvaluator    *bexpr.Evaluator `json:"-" bexpr:"-"`
-	disabledEvaluator *bexpr.Evaluator `json:"-" bexpr:"-"`
+	valueEvaluator    *Evaluator `json:"-" bexpr:"-"`
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
 }
 
 func (feature *Checkbox) Validate(tag string, i any) (changed bool, err error) {
+	var disabledChanged bool
+
+	if changed, err = feature.EvaluateCondition(i); err != nil {
+		return
+	}
+	disabledChanged, err = feature.EvaluateDisabledOn(i)
+	return changed || disabledChanged, err
+}
+
+func (feature *Checkbox) EvaluateCondition(i any) (changed bool, err error) {
 	var result any
 	var b bool
 
@@ -32,12 +42,19 @@ func (feature *Checkbox) Validate(tag string, i any) (changed bool, err error) {
 			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, err)
 		}
 		b, _ = bexpr.CoerceBool(result)
-		changed = changed || feature.Value != b
+		changed = feature.Value != b
 		feature.Value = b
 		//if changed {
 		//	fmt.Printf("%s: Result of expression %q evaluation: %t\n", feature.Tag, feature.Condition, b)
 		//}
 	}
+	return changed, nil
+}
+
+func (feature *Checkbox) EvaluateDisabledOn(i any) (changed bool, err error) {
+	var result any
+	var b bool
+
 	if len(feature.DisabledOn) > 0 {
 		if feature.disabledEvaluator == nil {
 			fmt.Printf("%s: failed to create evaluator for expression %q\n", feature.Tag, feature.DisabledOn)
@@ -45,15 +62,12 @@ func (feature *Checkbox) Validate(tag string, i any) (changed bool, err error) {
 		}
 		result, err = feature.disabledEvaluator.Evaluate(i)
 		if err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, err)
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
 		}
 		if b, err = bexpr.CoerceBool(result); err != nil {
-			//fmt.Printf("%s %v %T\n", feature.DisabledOn, err, result)
-			//b = false
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, err)
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
 		}
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Disabled != b
+		changed = feature.Disabled != b
 		feature.Disabled = b
 	}
 	return changed, nil
@@ -62,7 +76,7 @@ func (feature *Checkbox) Validate(tag string, i any) (changed bool, err error) {
 func (feature *Checkbox) ApplyDefaults() error {
 	// apply defaults
 	if feature.Condition != "" {
-		eval, err := bexpr.CreateEvaluator(feature.Condition)
+		eval, err := NewEvaluator(feature.Condition)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
 		} else {
@@ -71,7 +85,7 @@ func (feature *Checkbox) ApplyDefaults() error {
 	}
 
 	if feature.DisabledOn != "" {
-		eval, err := bexpr.CreateEvaluator(feature.DisabledOn)
+		eval, err := NewEvaluator(feature.DisabledOn)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
 		} else {
@@ -134,6 +148,14 @@ func (feature *Checkbox) GetInfoUrl() string {
 	return feature.InfoUrl
 }
 
+func (feature *Checkbox) GetCondition() string {
+	return feature.Condition
+}
+
+func (feature *Checkbox) GetDisabledOn() string {
+	return feature.DisabledOn
+}
+
 func (feature *Checkbox) MarshalJSON() ([]byte, error) {
 	type jsonFeature struct {
 		Type     string `json:"type"`
@@ -16,8 +16,8 @@ type Checkform struct {
 	//Condition         string           `json:"condition,omitempty"`
 	DisabledOn   string `json:"disabled_on,omitempty"`
 	HideDisabled bool   `json:"hide_disabled,omitempty"`
-	//valueEvaluator    *bexpr.Evaluator `json:"-" bexpr:"-"`
-	disabledEvaluator *bexpr.Evaluator `json:"-" bexpr:"-"`
+	//valueEvaluator    *Evaluator `json:"-" bexpr:"-"`
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
 
 	Properties   map[string]Feature `json:"-" bexpr:"properties"`
 	FeatureOrder []string           `json:"feature_order"`
@@ -28,9 +28,6 @@ type Checkform struct {
 }
 
 func (feature *Checkform) Validate(tag string, i any) (changed bool, err error) {
-	var result any
-	var b bool
-
 	if feature.Tag != "" && feature.Tag == tag {
 		return
 	}
@@ -51,27 +48,37 @@ func (feature *Checkform) Validate(tag string, i any) (changed bool, err error)
 			feature.Value = b
 		}
 	*/
+	subchanged, err := feature.EvaluateDisabledOn(i)
+	return changed || subchanged, err
+}
+
+func (feature *Checkform) EvaluateCondition(i any) (changed bool, err error) {
+	return
+}
+
+func (feature *Checkform) EvaluateDisabledOn(i any) (changed bool, err error) {
+	var result any
+	var b bool
+
 	if len(feature.DisabledOn) > 0 {
 		result, err = feature.disabledEvaluator.Evaluate(i)
 		if err != nil {
 			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
 		}
 		if b, err = bexpr.CoerceBool(result); err != nil {
-			//fmt.Printf("%s %v %T\n", feature.DisabledOn, err, result)
 			b = false
 		}
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Disabled != b
+		changed = feature.Disabled != b
 		feature.Disabled = b
 	}
-	return
+	return changed, nil
 }
 
 func (feature *Checkform) ApplyDefaults() error {
 	// apply defaults
 	/*
 		if feature.Condition != "" {
-			eval, err := bexpr.CreateEvaluator(feature.Condition)
+			eval, err := NewEvaluator(feature.Condition)
 			if err != nil {
 				return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
 			} else {
@@ -80,7 +87,7 @@ func (feature *Checkform) ApplyDefaults() error {
 		}
 	*/
 	if feature.DisabledOn != "" {
-		eval, err := bexpr.CreateEvaluator(feature.DisabledOn)
+		eval, err := NewEvaluator(feature.DisabledOn)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
 		} else {
@@ -223,6 +230,14 @@ func (feature *Checkform) GetTitle() string {
 	return feature.Title
 }
 
+func (feature *Checkform) GetCondition() string {
+	return ""
+}
+
+func (feature *Checkform) GetDisabledOn() string {
+	return feature.DisabledOn
+}
+
 func (feature *Checkform) UnmarshalJSON(bytes []byte) (err error) {
 	marshaller := &CheckformFormly{feature}
 	return marshaller.UnmarshalJSON(bytes)
@@ -16,8 +16,8 @@ type Checklist struct {
 	//Condition         string           `json:"condition,omitempty"`
 	DisabledOn   string `json:"disabled_on,omitempty"`
 	HideDisabled bool   `json:"hide_disabled,omitempty"`
-	//valueEvaluator    *bexpr.Evaluator `json:"-" bexpr:"-"`
-	disabledEvaluator *bexpr.Evaluator `json:"-" bexpr:"-"`
+	//valueEvaluator    *Evaluator `json:"-" bexpr:"-"`
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
 
 	//Properties []any `json:"properties" bexpr:"properties"`
 	Enum []*Checkbox `json:"enum" bexpr:"enum"`
@@ -44,17 +44,25 @@ func (feature *Checklist) Validate(tag string, i any) (changed bool, err error)
 			feature.Value = b
 		}
 	*/
+	subchanged, err := feature.EvaluateDisabledOn(i)
+	//fmt.Printf("Checklist %s: changed=%t, disabled=%t, childrenDisabled=%t\n", feature.Tag, changed, feature.Disabled, allChildrenDisabled)
+	return changed || subchanged, err
+}
+
+func (feature *Checklist) EvaluateCondition(i any) (changed bool, err error) {
+	return
+}
+
+func (feature *Checklist) EvaluateDisabledOn(i any) (changed bool, err error) {
 	if len(feature.DisabledOn) > 0 {
-		result, eval_err := feature.disabledEvaluator.Evaluate(i)
-		if eval_err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, eval_err)
+		result, err := feature.disabledEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
 		}
 		b, _ := bexpr.CoerceBool(result)
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Disabled != b
+		changed = feature.Disabled != b
 		feature.Disabled = b
 	}
-	//fmt.Printf("Checklist %s: changed=%t, disabled=%t, childrenDisabled=%t\n", feature.Tag, changed, feature.Disabled, allChildrenDisabled)
 	return
 }
 
@@ -62,7 +70,7 @@ func (feature *Checklist) ApplyDefaults() error {
 	// apply defaults
 	/*
 		if feature.Condition != "" {
-			eval, err := bexpr.CreateEvaluator(feature.Condition)
+			eval, err := NewEvaluator(feature.Condition)
 			if err != nil {
 				return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
 			} else {
@@ -71,7 +79,7 @@ func (feature *Checklist) ApplyDefaults() error {
 		}
 	*/
 	if feature.DisabledOn != "" {
-		eval, err := bexpr.CreateEvaluator(feature.DisabledOn)
+		eval, err := NewEvaluator(feature.DisabledOn)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
 		} else {
@@ -190,6 +198,14 @@ func (feature *Checklist) GetTitle() string {
 	return feature.Title
 }
 
+func (feature *Checklist) GetCondition() string {
+	return ""
+}
+
+func (feature *Checklist) GetDisabledOn() string {
+	return feature.DisabledOn
+}
+
 //func (feature *Checklist) UnmarshalJSON(bytes []byte) (err error) {
 //	marshaller := &ChecklistFormly{feature}
 //	return marshaller.UnmarshalJSON(bytes)
@@ -3,68 +3,403 @@ package converter
 import (
 	"archive/zip"
 	"bytes"
+	"encoding/xml"
 	"fmt"
 	"io"
-	"os"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
-func addFile(zipWriter *zip.Writer, filename string, src io.Reader) {
-	w1, err := zipWriter.Create(filename)
+const (
+	docxDocument      = "word/document.xml"
+	docxStyles        = "word/styles.xml"
+	docxDocumentRels  = "word/_rels/document.xml.rels"
+	docxHyperlinkType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+
+	// width of the text of an A4 page with 2 cm margins, in twips
+	docxDefaultTextWidth = 9638
+)
+
+// DocxOptions are the options of MarkdownToDocx.
+type DocxOptions struct {
+	// ReferenceDoc is a docx the styles, headers, footers and page setup
+	// are taken from, as pandoc --reference-doc does. A plain document is
+	// used when empty.
+	ReferenceDoc []byte
+	// TableStyle is the style of the tables, "Table" when empty or missing
+	// from the reference document.
+	TableStyle string
+}
+
+var (
+	bodyRegexp   = regexp.MustCompile(`<w:body(?:\s[^>]*)?>`)
+	pgSzRegexp   = regexp.MustCompile(`<w:pgSz\s[^>]*w:w="(\d+)"`)
+	pgMarRegexp  = regexp.MustCompile(`<w:pgMar\s[^>]*>`)
+	marginRegexp = regexp.MustCompile(`w:(left|right)="(\d+)"`)
+)
+
+// docxStyle identifies a style of the reference document by type and name.
+type docxStyle struct {
+	Type string
+	Name string
+}
+
+type docxWriter struct {
+	styles     map[docxStyle]string
+	tableStyle string
+	textWidth  int
+	links      []string
+}
+
+// MarkdownToDocx converts markdown to a docx document, see parseMarkdown for
+// the syntax supported. The body of the reference document is replaced, while
+// its last section properties are kept.
+func MarkdownToDocx(markdown string, options DocxOptions) ([]byte, error) {
+	reference := options.ReferenceDoc
+	if len(reference) == 0 {
+		reference = defaultReferenceDoc
+	}
+	archive, err := zip.NewReader(bytes.NewReader(reference), int64(len(reference)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference doc: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		files[f.Name], err = io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	document, ok := files[docxDocument]
+	if !ok {
+		return nil, fmt.Errorf("invalid reference doc: %s not found", docxDocument)
+	}
+	start := bodyRegexp.FindIndex(document)
+	end := bytes.LastIndex(document, []byte("</w:body>"))
+	if start == nil || end < start[1] {
+		return nil, fmt.Errorf("invalid reference doc: %s has no body", docxDocument)
+	}
+	sectPr := sectionProperties(string(document[start[1]:end]))
+
+	styles, err := readStyles(files[docxStyles])
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	w := &docxWriter{
+		styles:    styles,
+		textWidth: textWidth(sectPr),
 	}
-	if _, err := io.Copy(w1, src); err != nil {
-		panic(err)
+	for _, name := range []string{options.TableStyle, "Table"} {
+		if w.tableStyle = w.style("table", name); w.tableStyle != "" {
+			break
+		}
 	}
+
+	var body bytes.Buffer
+	body.Write(document[:start[1]])
+	w.writeBlocks(&body, parseMarkdown(markdown))
+	body.WriteString(sectPr)
+	body.Write(document[end:])
+	files[docxDocument] = body.Bytes()
+
+	if len(w.links) > 0 {
+		rels, ok := files[docxDocumentRels]
+		if !ok {
+			return nil, fmt.Errorf("invalid reference doc: %s not found", docxDocumentRels)
+		}
+		files[docxDocumentRels] = w.addLinks(rels)
+	}
+
+	var out bytes.Buffer
+	zipWriter := zip.NewWriter(&out)
+	for _, f := range archive.File {
+		fw, err := zipWriter.Create(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(files[f.Name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
 }
 
-func FixDocxTableStyle(inputFile string, outputFile string) error {
-	if _, err := os.Stat(inputFile); err == nil {
-		if b, err := os.ReadFile(inputFile); err == nil {
-			s := bytes.NewReader(b)
+// sectionProperties returns the last w:sectPr of the body, the one holding
+// the page setup and the references to headers and footers.
+func sectionProperties(body string) string {
+	start := strings.LastIndex(body, "<w:sectPr")
+	if start < 0 {
+		return ""
+	}
+	if end := strings.Index(body[start:], "</w:sectPr>"); end >= 0 {
+		return body[start : start+end+len("</w:sectPr>")]
+	}
+	if end := strings.Index(body[start:], "/>"); end >= 0 {
+		return body[start : start+end+len("/>")]
+	}
+	return ""
+}
 
-			read, err := zip.NewReader(s, s.Size())
+func textWidth(sectPr string) int {
+	m := pgSzRegexp.FindStringSubmatch(sectPr)
+	if m == nil {
+		return docxDefaultTextWidth
+	}
+	width, _ := strconv.Atoi(m[1])
+	for _, margin := range marginRegexp.FindAllStringSubmatch(pgMarRegexp.FindString(sectPr), -1) {
+		v, _ := strconv.Atoi(margin[2])
+		width -= v
+	}
+	if width <= 0 {
+		return docxDefaultTextWidth
+	}
+	return width
+}
 
-			if err != nil {
-				return err
+// readStyles maps the styles of styles.xml, by type and lowercase name and
+// by type and id, to their ids. Localized documents have localized ids, as
+// Titolo1 for "heading 1", so the styles are looked up by name first.
+func readStyles(data []byte) (map[docxStyle]string, error) {
+	var doc struct {
+		Styles []struct {
+			Type string `xml:"type,attr"`
+			ID   string `xml:"styleId,attr"`
+			Name struct {
+				Val string `xml:"val,attr"`
+			} `xml:"name"`
+		} `xml:"style"`
+	}
+	styles := make(map[docxStyle]string)
+	if len(data) == 0 {
+		return styles, nil
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid reference doc: %v", err)
+	}
+	for _, s := range doc.Styles {
+		styles[docxStyle{s.Type, "id:" + s.ID}] = s.ID
+		if name := strings.ToLower(s.Name.Val); name != "" {
+			if _, ok := styles[docxStyle{s.Type, name}]; !ok {
+				styles[docxStyle{s.Type, name}] = s.ID
 			}
+		}
+	}
+	return styles, nil
+}
+
+// style returns the id of the style of the given type with the given name
+// or id, an empty string if the reference document does not define it.
+func (w *docxWriter) style(styleType string, name string) string {
+	if name == "" {
+		return ""
+	}
+	if id, ok := w.styles[docxStyle{styleType, strings.ToLower(name)}]; ok {
+		return id
+	}
+	return w.styles[docxStyle{styleType, "id:" + name}]
+}
 
-			archive, err := os.Create(outputFile)
-			if err != nil {
-				return err
+// paragraphStyle returns the first of the paragraph styles defined.
+func (w *docxWriter) paragraphStyle(names ...string) string {
+	for _, name := range names {
+		if id := w.style("paragraph", name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+func (w *docxWriter) writeBlocks(buf *bytes.Buffer, blocks []block) {
+	afterHeading := true
+	for _, b := range blocks {
+		switch b.Kind {
+		case blockHeading:
+			style := w.paragraphStyle(fmt.Sprintf("Heading %d", b.Level))
+			w.writeParagraph(buf, style, "", "", b.Inlines)
+			afterHeading = true
+			continue
+
+		case blockParagraph:
+			style := w.paragraphStyle(b.Style)
+			if style == "" && afterHeading {
+				style = w.paragraphStyle("First Paragraph", "Body Text")
+			} else if style == "" {
+				style = w.paragraphStyle("Body Text")
 			}
-			defer archive.Close()
-
-			zipWriter := zip.NewWriter(archive)
-			defer zipWriter.Close()
-
-			// Iterate through the files in the zip archive
-			for _, f := range read.File {
-				// Open the current file
-				v, err := f.Open()
-				if err != nil {
-					return err
-				}
-				defer v.Close()
-
-				if f.Name == "word/document.xml" {
-					// Read the contents of the file
-					b, err := io.ReadAll(v)
-					if err != nil {
-						return err
-					}
-
-					addFile(zipWriter, f.Name, bytes.NewReader([]byte(strings.ReplaceAll(string(b),
-						"<w:tblStyle w:val=\"Table\" />",
-						"<w:tblStyle w:val=\"StileTable\" />"))))
-				} else {
-					addFile(zipWriter, f.Name, v)
-				}
+			w.writeParagraph(buf, style, "", "", b.Inlines)
+
+		case blockListItem:
+			marker := "•"
+			if b.Ordered {
+				marker = strconv.Itoa(b.Number) + "."
+			}
+			indent := fmt.Sprintf(`<w:ind w:left="%d" w:hanging="360"/>`, 720*(b.Level+1))
+			inlines := append([]inline{{Text: marker + " "}}, b.Inlines...)
+			w.writeParagraph(buf, w.paragraphStyle(b.Style, "Compact", "Body Text"), indent, "", inlines)
+
+		case blockQuote:
+			w.writeParagraph(buf, w.paragraphStyle(b.Style, "Block Text"), "", "", b.Inlines)
+
+		case blockRule:
+			buf.WriteString(`<w:p><w:pPr><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="auto"/></w:pBdr></w:pPr></w:p>`)
+
+		case blockTable:
+			w.writeTable(buf, b.Table)
+		}
+		afterHeading = false
+	}
+}
+
+// writeParagraph writes a paragraph; indent is a w:ind element, jc the
+// justification.
+func (w *docxWriter) writeParagraph(buf *bytes.Buffer, style string, indent string, jc string, inlines []inline) {
+	buf.WriteString("<w:p>")
+	if style != "" || indent != "" || jc != "" {
+		buf.WriteString("<w:pPr>")
+		if style != "" {
+			fmt.Fprintf(buf, `<w:pStyle w:val="%s"/>`, escape(style))
+		}
+		buf.WriteString(indent)
+		if jc != "" {
+			fmt.Fprintf(buf, `<w:jc w:val="%s"/>`, jc)
+		}
+		buf.WriteString("</w:pPr>")
+	}
+	for _, r := range inlines {
+		w.writeRun(buf, r)
+	}
+	buf.WriteString("</w:p>")
+}
+
+func (w *docxWriter) writeRun(buf *bytes.Buffer, r inline) {
+	r.Link = safeLink(r.Link)
+	if r.Link != "" {
+		w.links = append(w.links, r.Link)
+		fmt.Fprintf(buf, `<w:hyperlink r:id="rIdLink%d">`, len(w.links))
+		if r.Style == "" {
+			r.Style = "Hyperlink"
+		}
+	}
+
+	buf.WriteString("<w:r>")
+	style := w.style("character", r.Style)
+	if r.Code {
+		style = w.style("character", "Verbatim Char")
+	}
+	if style != "" || r.Bold || r.Italic {
+		buf.WriteString("<w:rPr>")
+		if style != "" {
+			fmt.Fprintf(buf, `<w:rStyle w:val="%s"/>`, escape(style))
+		}
+		if r.Bold {
+			buf.WriteString("<w:b/><w:bCs/>")
+		}
+		if r.Italic {
+			buf.WriteString("<w:i/><w:iCs/>")
+		}
+		buf.WriteString("</w:rPr>")
+	}
+	if r.Break {
+		buf.WriteString("<w:br/>")
+	} else {
+		buf.WriteString(`<w:t xml:space="preserve">`)
+		buf.WriteString(escape(r.Text))
+		buf.WriteString("</w:t>")
+	}
+	buf.WriteString("</w:r>")
+
+	if r.Link != "" {
+		buf.WriteString("</w:hyperlink>")
+	}
+}
+
+// writeTable writes a table whose column widths are proportional to the
+// dashes of the separator line, as pandoc does with long lines.
+func (w *docxWriter) writeTable(buf *bytes.Buffer, t *table) {
+	columns := len(t.Aligns)
+	total := 0
+	for _, width := range t.Widths {
+		total += width
+	}
+	widths := make([]int, columns)
+	for i, width := range t.Widths {
+		widths[i] = w.textWidth * width / total
+	}
+
+	hasHeader := false
+	for _, cell := range t.Header {
+		if len(cell) > 0 {
+			hasHeader = true
+		}
+	}
+
+	buf.WriteString("<w:tbl><w:tblPr>")
+	if w.tableStyle != "" {
+		fmt.Fprintf(buf, `<w:tblStyle w:val="%s"/>`, escape(w.tableStyle))
+	}
+	buf.WriteString(`<w:tblW w:w="5000" w:type="pct"/>`)
+	if hasHeader {
+		buf.WriteString(`<w:tblLook w:firstRow="1" w:lastRow="0" w:firstColumn="0" w:lastColumn="0" w:noHBand="0" w:noVBand="0" w:val="0020"/>`)
+	} else {
+		buf.WriteString(`<w:tblLook w:firstRow="0" w:lastRow="0" w:firstColumn="0" w:lastColumn="0" w:noHBand="0" w:noVBand="0" w:val="0000"/>`)
+	}
+	buf.WriteString("</w:tblPr><w:tblGrid>")
+	for _, width := range widths {
+		fmt.Fprintf(buf, `<w:gridCol w:w="%d"/>`, width)
+	}
+	buf.WriteString("</w:tblGrid>")
+
+	writeRow := func(cells [][]inline, header bool) {
+		buf.WriteString("<w:tr>")
+		if header {
+			buf.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+		}
+		for i := 0; i < columns; i++ {
+			fmt.Fprintf(buf, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/></w:tcPr>`, widths[i])
+			var inlines []inline
+			if i < len(cells) {
+				inlines = cells[i]
 			}
-			return nil
+			w.writeParagraph(buf, w.paragraphStyle("Compact"), "", t.Aligns[i], inlines)
+			buf.WriteString("</w:tc>")
 		}
+		buf.WriteString("</w:tr>")
+	}
+	if hasHeader {
+		writeRow(t.Header, true)
 	}
+	for _, row := range t.Rows {
+		writeRow(row, false)
+	}
+	buf.WriteString("</w:tbl>")
+}
+
+// addLinks adds the relationships of the hyperlinks to document.xml.rels.
+func (w *docxWriter) addLinks(rels []byte) []byte {
+	var buf bytes.Buffer
+	for i, link := range w.links {
+		fmt.Fprintf(&buf, `<Relationship Id="rIdLink%d" Type="%s" Target="%s" TargetMode="External"/>`,
+			i+1, docxHyperlinkType, escape(link))
+	}
+	end := bytes.LastIndex(rels, []byte("</Relationships>"))
+	if end < 0 {
+		return rels
+	}
+	return append(append(append([]byte{}, rels[:end]...), buf.Bytes()...), rels[end:]...)
+}
 
-	return fmt.Errorf("cannot open %s", inputFile)
+func escape(s string) string {
+	var buf strings.Builder
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
 }
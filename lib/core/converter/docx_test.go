@@ -0,0 +1,76 @@
+package converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// docxFiles returns the files of a docx by name.
+func docxFiles(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestMarkdownToDocx(t *testing.T) {
+	out, err := MarkdownToDocx("# T <x>\n\ntext & **b**\n\n| A | B |\n|:--|--:|\n| 1 | 2 |\n", DocxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := docxFiles(t, out)
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/document.xml", "word/_rels/document.xml.rels", "word/styles.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	document := files["word/document.xml"]
+	for _, want := range []string{
+		`<w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t xml:space="preserve">T &lt;x&gt;</w:t>`,
+		`<w:t xml:space="preserve">text &amp; </w:t>`,
+		`<w:b/>`,
+		`<w:tblStyle w:val="Table"/>`,
+		`<w:jc w:val="right"/>`,
+	} {
+		if !strings.Contains(document, want) {
+			t.Errorf("missing %s in\n%s", want, document)
+		}
+	}
+}
+
+func TestMarkdownToDocxReferenceDoc(t *testing.T) {
+	plain, err := MarkdownToDocx("text\n", DocxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the plain document is a reference doc as well
+	out, err := MarkdownToDocx("other\n", DocxOptions{ReferenceDoc: plain, TableStyle: "Missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := docxFiles(t, out)
+	if files["word/styles.xml"] != docxFiles(t, plain)["word/styles.xml"] {
+		t.Errorf("the styles of the reference doc are not kept")
+	}
+	if !strings.Contains(files["word/document.xml"], "other") || strings.Contains(files["word/document.xml"], ">text<") {
+		t.Errorf("the body of the reference doc is not replaced")
+	}
+}
@@ -0,0 +1,260 @@
+package converter
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HtmlOptions are the options of MarkdownToHtml.
+type HtmlOptions struct {
+	Title string
+	Lang  string
+	// Stylesheet is appended to the default stylesheet, which gives the
+	// custom styles the look of DefaultPdfStyles and Styles
+	Stylesheet string
+	Styles     map[string]PdfStyle
+}
+
+const htmlStylesheet = `body { font-family: Helvetica, Arial, sans-serif; font-size: 10.5pt; line-height: 1.35; max-width: 48em; margin: 2em auto; padding: 0 1em; color: #000; }
+h1, h2, h3, h4, h5, h6 { margin: 1.2em 0 0.4em; }
+table { border-collapse: collapse; width: 100%; margin: 0.5em 0 1em; font-size: 9.5pt; border-top: 1px solid #000; border-bottom: 1px solid #000; }
+th, td { padding: 3pt; vertical-align: top; border-bottom: 1px solid #bbb; }
+th { background: #ebebeb; border-bottom: 1px solid #000; }
+blockquote { margin: 0 0 0 1.5em; padding-left: 0.75em; border-left: 2px solid #bbb; color: #595959; }
+a { color: #0563c1; }
+`
+
+// CssClass returns the css class of a custom style: its name, with the
+// characters other than letters, digits, - and _ replaced by -.
+func CssClass(style string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, style)
+}
+
+// MarkdownToHtml converts markdown to a standalone html document, see
+// parseMarkdown for the syntax supported. Custom styles become classes, see
+// CssClass.
+func MarkdownToHtml(markdown string, options HtmlOptions) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n")
+	if options.Lang != "" {
+		fmt.Fprintf(&b, "<html lang=\"%s\">\n", html.EscapeString(options.Lang))
+	} else {
+		b.WriteString("<html>\n")
+	}
+	b.WriteString("<head>\n<meta charset=\"utf-8\">\n<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(options.Title))
+	b.WriteString("<style>\n")
+	b.WriteString(htmlStylesheet)
+	writeStyleRules(&b, options.Styles)
+	if options.Stylesheet != "" {
+		b.WriteString(options.Stylesheet)
+		b.WriteString("\n")
+	}
+	b.WriteString("</style>\n</head>\n<body>\n")
+	writeHtmlBlocks(&b, parseMarkdown(markdown))
+	b.WriteString("</body>\n</html>\n")
+	return []byte(b.String()), nil
+}
+
+// writeStyleRules writes the css rules of the custom styles.
+func writeStyleRules(b *strings.Builder, overrides map[string]PdfStyle) {
+	styles := make(map[string]PdfStyle)
+	for name, style := range DefaultPdfStyles {
+		styles[name] = style
+	}
+	for name, style := range overrides {
+		styles[name] = style
+	}
+	names := make([]string, 0, len(styles))
+	for name := range styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		style := styles[name]
+		rules := make([]string, 0)
+		if style.Color != "" {
+			rules = append(rules, "color: "+style.Color)
+		}
+		if style.Size > 0 {
+			rules = append(rules, "font-size: "+strconv.FormatFloat(style.Size, 'f', -1, 64)+"pt")
+		}
+		if style.Bold {
+			rules = append(rules, "font-weight: bold")
+		}
+		if style.Italic {
+			rules = append(rules, "font-style: italic")
+		}
+		if style.Align != "" {
+			rules = append(rules, "text-align: "+style.Align)
+		}
+		if len(rules) > 0 {
+			fmt.Fprintf(b, ".%s { %s; }\n", CssClass(name), strings.Join(rules, "; "))
+		}
+	}
+}
+
+func writeHtmlBlocks(b *strings.Builder, blocks []block) {
+	// levels of the open lists, and the type of each
+	lists := make([]string, 0)
+	closeLists := func(depth int) {
+		for len(lists) > depth {
+			fmt.Fprintf(b, "</li>\n</%s>\n", lists[len(lists)-1])
+			lists = lists[:len(lists)-1]
+		}
+	}
+	div := ""
+
+	for _, bl := range blocks {
+		if bl.Kind != blockListItem {
+			closeLists(0)
+		}
+		if bl.Style != div {
+			closeLists(0)
+			if div != "" {
+				b.WriteString("</div>\n")
+			}
+			if bl.Style != "" {
+				fmt.Fprintf(b, "<div class=\"%s\">\n", CssClass(bl.Style))
+			}
+			div = bl.Style
+		}
+
+		switch bl.Kind {
+		case blockHeading:
+			fmt.Fprintf(b, "<h%d>", bl.Level)
+			writeHtmlInlines(b, bl.Inlines)
+			fmt.Fprintf(b, "</h%d>\n", bl.Level)
+
+		case blockParagraph:
+			b.WriteString("<p>")
+			writeHtmlInlines(b, bl.Inlines)
+			b.WriteString("</p>\n")
+
+		case blockListItem:
+			list := "ul"
+			if bl.Ordered {
+				list = "ol"
+			}
+			closeLists(bl.Level + 1)
+			if len(lists) == bl.Level+1 {
+				if lists[bl.Level] == list {
+					b.WriteString("</li>\n")
+				} else {
+					closeLists(bl.Level)
+				}
+			}
+			for len(lists) < bl.Level+1 {
+				if list == "ol" && bl.Number != 1 && len(lists) == bl.Level {
+					fmt.Fprintf(b, "<ol start=\"%d\">\n", bl.Number)
+				} else {
+					fmt.Fprintf(b, "<%s>\n", list)
+				}
+				lists = append(lists, list)
+			}
+			b.WriteString("<li>")
+			writeHtmlInlines(b, bl.Inlines)
+
+		case blockQuote:
+			b.WriteString("<blockquote><p>")
+			writeHtmlInlines(b, bl.Inlines)
+			b.WriteString("</p></blockquote>\n")
+
+		case blockRule:
+			b.WriteString("<hr>\n")
+
+		case blockTable:
+			writeHtmlTable(b, bl.Table)
+		}
+	}
+	closeLists(0)
+	if div != "" {
+		b.WriteString("</div>\n")
+	}
+}
+
+// writeHtmlTable writes a table whose column widths are proportional to the
+// dashes of the separator line.
+func writeHtmlTable(b *strings.Builder, t *table) {
+	total := 0
+	for _, width := range t.Widths {
+		total += width
+	}
+	b.WriteString("<table>\n<colgroup>\n")
+	for _, width := range t.Widths {
+		fmt.Fprintf(b, "<col style=\"width: %d%%\">\n", 100*width/total)
+	}
+	b.WriteString("</colgroup>\n")
+
+	writeRow := func(cells [][]inline, tag string) {
+		b.WriteString("<tr>")
+		for i, align := range t.Aligns {
+			if align != "" {
+				fmt.Fprintf(b, "<%s style=\"text-align: %s\">", tag, align)
+			} else {
+				fmt.Fprintf(b, "<%s>", tag)
+			}
+			if i < len(cells) {
+				writeHtmlInlines(b, cells[i])
+			}
+			fmt.Fprintf(b, "</%s>", tag)
+		}
+		b.WriteString("</tr>\n")
+	}
+	for _, cell := range t.Header {
+		if len(cell) > 0 {
+			b.WriteString("<thead>\n")
+			writeRow(t.Header, "th")
+			b.WriteString("</thead>\n")
+			break
+		}
+	}
+	b.WriteString("<tbody>\n")
+	for _, row := range t.Rows {
+		writeRow(row, "td")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+}
+
+func writeHtmlInlines(b *strings.Builder, inlines []inline) {
+	for _, r := range inlines {
+		r.Link = safeLink(r.Link)
+		if r.Break {
+			b.WriteString("<br>\n")
+			continue
+		}
+		closing := make([]string, 0)
+		open := func(tag string, attrs string) {
+			fmt.Fprintf(b, "<%s%s>", tag, attrs)
+			closing = append(closing, "</"+tag+">")
+		}
+		if r.Link != "" {
+			open("a", fmt.Sprintf(" href=\"%s\"", html.EscapeString(r.Link)))
+		}
+		if r.Style != "" {
+			open("span", fmt.Sprintf(" class=\"%s\"", CssClass(r.Style)))
+		}
+		if r.Bold {
+			open("strong", "")
+		}
+		if r.Italic {
+			open("em", "")
+		}
+		if r.Code {
+			open("code", "")
+		}
+		b.WriteString(html.EscapeString(r.Text))
+		for i := len(closing) - 1; i >= 0; i-- {
+			b.WriteString(closing[i])
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToHtml(t *testing.T) {
+	out, err := MarkdownToHtml("# T <x>\n\n[Rosso]{custom-style=\"Danger\"} & **b**\n\n| A | B |\n|:--|--:|\n| 1 | 2 |\n",
+		HtmlOptions{Title: "Doc <1>", Lang: "it", Stylesheet: ".Extra { color: red; }"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(out)
+	for _, want := range []string{
+		`<html lang="it">`,
+		`<title>Doc &lt;1&gt;</title>`,
+		`.Danger { color: #c00000; }`,
+		`.Extra { color: red; }`,
+		`<h1>T &lt;x&gt;</h1>`,
+		`<p><span class="Danger">Rosso</span> &amp; <strong>b</strong></p>`,
+		`<th style="text-align: left">A</th><th style="text-align: right">B</th>`,
+		`<td style="text-align: left">1</td><td style="text-align: right">2</td>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %s in\n%s", want, html)
+		}
+	}
+}
+
+func TestCssClass(t *testing.T) {
+	if got := CssClass("Nota bene"); strings.ContainsAny(got, " \"<>") {
+		t.Errorf("CssClass(%q) = %q is not a class name", "Nota bene", got)
+	}
+}
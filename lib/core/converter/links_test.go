@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+const linksMarkdown = "[a](javascript:alert(1)) [b](JavaScript:alert(2)) [c](https://example.com/x) [d](mailto:a@example.com) [e](other.md)\n"
+
+func TestSafeLink(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com":  "https://example.com",
+		"http://example.com":   "http://example.com",
+		"mailto:a@example.com": "mailto:a@example.com",
+		"other.md#section":     "other.md#section",
+		"javascript:alert(1)":  "",
+		"JAVASCRIPT:alert(1)":  "",
+		"data:text/html,x":     "",
+		"java\tscript:x":       "",
+	}
+	for link, want := range tests {
+		if got := safeLink(link); got != want {
+			t.Errorf("safeLink(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
+
+// checkLinks fails if the output of a writer misses the safe links or keeps
+// the scripts.
+func checkLinks(t *testing.T, format string, out string) {
+	t.Helper()
+	if strings.Contains(strings.ToLower(out), "javascript") {
+		t.Errorf("%s: a javascript link is written", format)
+	}
+	for _, link := range []string{"https://example.com/x", "mailto:a@example.com"} {
+		if !strings.Contains(out, link) {
+			t.Errorf("%s: the link %s is missing", format, link)
+		}
+	}
+}
+
+func TestLinksHtml(t *testing.T) {
+	out, err := MarkdownToHtml(linksMarkdown, HtmlOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLinks(t, "html", string(out))
+	if !strings.Contains(string(out), `href="other.md"`) {
+		t.Errorf("html: the relative link is missing")
+	}
+}
+
+func TestLinksPdf(t *testing.T) {
+	out, err := MarkdownToPdf(linksMarkdown, PdfOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLinks(t, "pdf", string(out))
+}
+
+func TestLinksDocx(t *testing.T) {
+	out, err := MarkdownToDocx(linksMarkdown, DocxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all strings.Builder
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(&all, rc)
+		rc.Close()
+	}
+	checkLinks(t, "docx", all.String())
+}
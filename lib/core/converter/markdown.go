@@ -0,0 +1,456 @@
+package converter
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The markdown understood by MarkdownToDocx is the subset of pandoc markdown
+// the report templates are written in: ATX and setext headings, paragraphs,
+// bullet and ordered lists, thematic breaks, block quotes, pipe tables,
+// fenced divs and bracketed spans with a custom-style attribute, strong and
+// emphasis, code spans, links and backslash escapes.
+
+const (
+	blockParagraph = iota
+	blockHeading
+	blockListItem
+	blockQuote
+	blockRule
+	blockTable
+)
+
+// inline is a run of text sharing the same formatting.
+type inline struct {
+	Text   string
+	Bold   bool
+	Italic bool
+	Code   bool
+	Break  bool
+	Style  string
+	Link   string
+}
+
+// safeLink returns the link target if it is relative or uses the http,
+// https or mailto scheme, an empty string otherwise: the writers must not
+// turn a template into a script.
+func safeLink(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return link
+	}
+	return ""
+}
+
+type block struct {
+	Kind    int
+	Level   int
+	Ordered bool
+	Number  int
+	Style   string
+	Inlines []inline
+	Table   *table
+}
+
+type table struct {
+	Aligns []string
+	Widths []int
+	Header [][]inline
+	Rows   [][][]inline
+}
+
+var (
+	headingRegexp     = regexp.MustCompile(`^(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	setextRegexp      = regexp.MustCompile(`^\s*(=+|-+)\s*$`)
+	divRegexp         = regexp.MustCompile(`^\s*:::+\s*(.*?)\s*:*\s*$`)
+	customStyleRegexp = regexp.MustCompile(`custom-style\s*=\s*"([^"]*)"`)
+	listRegexp        = regexp.MustCompile(`^(\s*)(?:([-*+])|(\d+)[.)])\s+(.*)$`)
+	ruleRegexp        = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	separatorRegexp   = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$`)
+	quoteRegexp       = regexp.MustCompile(`^\s*>\s?(.*)$`)
+)
+
+type markdownParser struct {
+	blocks []block
+	lines  []string
+	kind   int
+	item   block
+	styles []string
+}
+
+// parseMarkdown splits the text in blocks.
+func parseMarkdown(text string) []block {
+	p := &markdownParser{}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+
+		if strings.TrimSpace(line) == "" {
+			p.flush()
+			continue
+		}
+		if m := divRegexp.FindStringSubmatch(line); m != nil {
+			p.flush()
+			if m[1] == "" {
+				if len(p.styles) > 0 {
+					p.styles = p.styles[:len(p.styles)-1]
+				}
+			} else {
+				style := ""
+				if s := customStyleRegexp.FindStringSubmatch(m[1]); s != nil {
+					style = s[1]
+				}
+				p.styles = append(p.styles, style)
+			}
+			continue
+		}
+		if p.kind == blockParagraph && len(p.lines) > 0 {
+			if m := setextRegexp.FindStringSubmatch(line); m != nil {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				p.blocks = append(p.blocks, block{Kind: blockHeading, Level: level, Style: p.style(), Inlines: parseInlines(joinLines(p.lines))})
+				p.lines = nil
+				continue
+			}
+		}
+		if m := headingRegexp.FindStringSubmatch(line); m != nil {
+			p.flush()
+			p.blocks = append(p.blocks, block{Kind: blockHeading, Level: len(m[1]), Style: p.style(), Inlines: parseInlines(m[2])})
+			continue
+		}
+		if strings.Contains(line, "|") && i+1 < len(lines) && separatorRegexp.MatchString(lines[i+1]) {
+			p.flush()
+			t := &table{Header: splitRow(line)}
+			for _, cell := range splitCells(strings.TrimSpace(lines[i+1])) {
+				cell = strings.TrimSpace(cell)
+				align := ""
+				switch {
+				case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+					align = "center"
+				case strings.HasSuffix(cell, ":"):
+					align = "right"
+				case strings.HasPrefix(cell, ":"):
+					align = "left"
+				}
+				t.Aligns = append(t.Aligns, align)
+				t.Widths = append(t.Widths, len(cell))
+			}
+			i += 2
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.Contains(lines[i], "|"); i++ {
+				t.Rows = append(t.Rows, splitRow(lines[i]))
+			}
+			i--
+			p.blocks = append(p.blocks, block{Kind: blockTable, Style: p.style(), Table: t})
+			continue
+		}
+		if ruleRegexp.MatchString(line) {
+			p.flush()
+			p.blocks = append(p.blocks, block{Kind: blockRule})
+			continue
+		}
+		if m := listRegexp.FindStringSubmatch(line); m != nil {
+			p.flush()
+			p.kind = blockListItem
+			p.item = block{Kind: blockListItem, Level: len(strings.ReplaceAll(m[1], "\t", "    ")) / 2, Style: p.style()}
+			if m[3] != "" {
+				p.item.Ordered = true
+				p.item.Number, _ = strconv.Atoi(m[3])
+			}
+			p.lines = []string{m[4]}
+			continue
+		}
+		if m := quoteRegexp.FindStringSubmatch(line); m != nil {
+			if p.kind != blockQuote {
+				p.flush()
+				p.kind = blockQuote
+			}
+			p.lines = append(p.lines, m[1])
+			continue
+		}
+		// lazy continuation of the current paragraph, list item or quote
+		p.lines = append(p.lines, line)
+	}
+	p.flush()
+	return p.blocks
+}
+
+// style returns the custom style of the innermost fenced div.
+func (p *markdownParser) style() string {
+	for i := len(p.styles) - 1; i >= 0; i-- {
+		if p.styles[i] != "" {
+			return p.styles[i]
+		}
+	}
+	return ""
+}
+
+func (p *markdownParser) flush() {
+	if len(p.lines) > 0 {
+		b := block{Kind: p.kind, Style: p.style()}
+		if p.kind == blockListItem {
+			b = p.item
+		}
+		b.Inlines = parseInlines(joinLines(p.lines))
+		p.blocks = append(p.blocks, b)
+	}
+	p.lines = nil
+	p.kind = blockParagraph
+}
+
+// joinLines joins the lines of a block: a line ending with two spaces or a
+// backslash is a hard break, the others are joined by a space.
+func joinLines(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		hard := strings.HasSuffix(line, "  ")
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			hard = true
+			line = strings.TrimSuffix(line, "\\")
+		}
+		b.WriteString(line)
+		if i < len(lines)-1 {
+			if hard {
+				b.WriteString("\n")
+			} else {
+				b.WriteString(" ")
+			}
+		}
+	}
+	return b.String()
+}
+
+// splitCells splits a table row on the pipes not escaped by a backslash.
+func splitCells(row string) []string {
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, "\\|") {
+		row = strings.TrimSuffix(row, "|")
+	}
+	cells := make([]string, 0)
+	start := 0
+	for i := 0; i < len(row); i++ {
+		switch row[i] {
+		case '\\':
+			i++
+		case '|':
+			cells = append(cells, row[start:i])
+			start = i + 1
+		}
+	}
+	return append(cells, row[start:])
+}
+
+func splitRow(row string) [][]inline {
+	cells := make([][]inline, 0)
+	for _, cell := range splitCells(strings.TrimSpace(row)) {
+		cells = append(cells, parseInlines(strings.TrimSpace(cell)))
+	}
+	return cells
+}
+
+func isEscapable(c byte) bool {
+	return strings.IndexByte("\\`*_{}[]()#+-.!|<>~\"'$:;,=?/@^&%", c) >= 0
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func parseInlines(s string) []inline {
+	return parseSpan(s, inline{})
+}
+
+// parseSpan parses the text of a span whose runs inherit the formatting f.
+func parseSpan(s string, f inline) []inline {
+	runs := make([]inline, 0)
+	var text strings.Builder
+	emit := func() {
+		if text.Len() > 0 {
+			r := f
+			r.Text = text.String()
+			runs = append(runs, r)
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isEscapable(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '\n':
+			emit()
+			r := f
+			r.Break = true
+			runs = append(runs, r)
+			i++
+			continue
+
+		case c == '`':
+			n := 1
+			for i+n < len(s) && s[i+n] == '`' {
+				n++
+			}
+			if end := strings.Index(s[i+n:], s[i:i+n]); end >= 0 {
+				emit()
+				r := f
+				r.Code = true
+				r.Text = strings.TrimSpace(s[i+n : i+n+end])
+				runs = append(runs, r)
+				i += 2*n + end
+				continue
+			}
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+
+		case c == '*' || c == '_':
+			n := 1
+			if i+1 < len(s) && s[i+1] == c {
+				n = 2
+			}
+			opens := i+n < len(s) && s[i+n] != ' ' && (c == '*' || i == 0 || !isWordChar(s[i-1]))
+			if opens {
+				if end := closingDelimiter(s, i+n, c, n); end >= 0 {
+					emit()
+					g := f
+					if n == 2 {
+						g.Bold = true
+					} else {
+						g.Italic = true
+					}
+					runs = append(runs, parseSpan(s[i+n:end], g)...)
+					i = end + n
+					continue
+				}
+			}
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+
+		case c == '[':
+			if end := closingBracket(s, i); end >= 0 && end+1 < len(s) {
+				rest := s[end+1:]
+				var closing byte
+				switch rest[0] {
+				case '{':
+					closing = '}'
+				case '(':
+					closing = ')'
+				}
+				if closing != 0 {
+					n := strings.IndexByte(rest, closing)
+					if closing == ')' {
+						n = closingParenthesis(rest, 0)
+					}
+					if n >= 0 {
+						g := f
+						if closing == '}' {
+							if m := customStyleRegexp.FindStringSubmatch(rest[1:n]); m != nil {
+								g.Style = m[1]
+							}
+						} else {
+							// the link target, without the optional title
+							g.Link, _, _ = strings.Cut(strings.TrimSpace(rest[1:n]), " ")
+							g.Link = strings.Trim(g.Link, "<>")
+						}
+						emit()
+						runs = append(runs, parseSpan(s[i+1:end], g)...)
+						i = end + 1 + n + 1
+						continue
+					}
+				}
+			}
+		}
+		text.WriteByte(c)
+		i++
+	}
+	emit()
+	return runs
+}
+
+// closingDelimiter returns the index of the n delimiters c closing an
+// emphasis opened before from, -1 if there is none.
+func closingDelimiter(s string, from int, c byte, n int) int {
+	for j := from; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case s[j] == '`':
+			if end := strings.IndexByte(s[j+1:], '`'); end >= 0 {
+				j += end + 1
+			}
+		case s[j] == c:
+			run := 1
+			for j+run < len(s) && s[j+run] == c {
+				run++
+			}
+			closes := s[j-1] != ' ' && j > from && (c == '*' || j+run >= len(s) || !isWordChar(s[j+run]))
+			if closes && run == n {
+				return j
+			}
+			if closes && run == 3 {
+				// ***: the closing delimiter is the part after the nested one
+				return j + 3 - n
+			}
+			j += run - 1
+		}
+	}
+	return -1
+}
+
+// closingParenthesis returns the index of the parenthesis closing the one
+// at i, skipping the balanced pairs of a link target and the quoted title.
+func closingParenthesis(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '"':
+			if s[j-1] == ' ' {
+				if end := strings.IndexByte(s[j+1:], '"'); end >= 0 {
+					j += end + 1
+				}
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// closingBracket returns the index of the bracket closing the one at i.
+func closingBracket(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
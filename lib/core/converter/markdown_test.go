@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMarkdownBlocks(t *testing.T) {
+	text := `# Title
+
+Setext
+------
+
+First line
+continued.
+
+- one
+  - nested
+3. three
+
+> quoted
+
+---
+
+::: {custom-style="Danger"}
+| A | B |
+|:--|--:|
+| 1 | 2 |
+:::
+`
+	blocks := parseMarkdown(text)
+	type summary struct {
+		Kind, Level int
+		Ordered     bool
+		Number      int
+		Style       string
+	}
+	got := make([]summary, 0, len(blocks))
+	for _, b := range blocks {
+		got = append(got, summary{b.Kind, b.Level, b.Ordered, b.Number, b.Style})
+	}
+	want := []summary{
+		{Kind: blockHeading, Level: 1},
+		{Kind: blockHeading, Level: 2},
+		{Kind: blockParagraph},
+		{Kind: blockListItem},
+		{Kind: blockListItem, Level: 1},
+		{Kind: blockListItem, Ordered: true, Number: 3},
+		{Kind: blockQuote},
+		{Kind: blockRule},
+		{Kind: blockTable, Style: "Danger"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got blocks %+v, want %+v", got, want)
+	}
+
+	if text := blocks[2].Inlines[0].Text; text != "First line continued." {
+		t.Errorf("the paragraph lines are not joined: %q", text)
+	}
+	table := blocks[8].Table
+	if !reflect.DeepEqual(table.Aligns, []string{"left", "right"}) || len(table.Rows) != 1 {
+		t.Errorf("unexpected table %+v", table)
+	}
+}
+
+func TestParseInlines(t *testing.T) {
+	got := parseInlines(`plain **bold** *italic* ` + "`code`" + ` [span]{custom-style="Note"} [link](https://example.com "title") \*escaped\*`)
+	want := []inline{
+		{Text: "plain "},
+		{Text: "bold", Bold: true},
+		{Text: " "},
+		{Text: "italic", Italic: true},
+		{Text: " "},
+		{Text: "code", Code: true},
+		{Text: " "},
+		{Text: "span", Style: "Note"},
+		{Text: " "},
+		{Text: "link", Link: "https://example.com"},
+		{Text: " *escaped*"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseLinkWithParentheses(t *testing.T) {
+	tests := []struct {
+		markdown string
+		want     []inline
+	}{
+		{`see [Foo](https://en.wikipedia.org/wiki/Foo_(bar)) here`, []inline{
+			{Text: "see "},
+			{Text: "Foo", Link: "https://en.wikipedia.org/wiki/Foo_(bar)"},
+			{Text: " here"},
+		}},
+		{`[x](javascript:alert(1))`, []inline{
+			{Text: "x", Link: "javascript:alert(1)"},
+		}},
+		{`[t](https://example.com "a (b") after`, []inline{
+			{Text: "t", Link: "https://example.com"},
+			{Text: " after"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := parseInlines(tt.markdown); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.markdown, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,709 @@
+package converter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"strconv"
+	"strings"
+)
+
+// A4 page layout, in points
+const (
+	pdfPageWidth    = 595.28
+	pdfPageHeight   = 841.89
+	pdfMarginX      = 56.69
+	pdfMarginTop    = 70.87
+	pdfMarginBottom = 62.36
+	pdfTextWidth    = pdfPageWidth - 2*pdfMarginX
+	pdfLogoHeight   = 28.0
+	pdfLogoMaxWidth = 150.0
+
+	pdfBodySize    = 10.5
+	pdfTableSize   = 9.5
+	pdfHeaderSize  = 8.5
+	pdfCellPadding = 3.0
+	pdfListIndent  = 18.0
+	pdfLineHeight  = 1.35
+)
+
+var pdfHeadingSizes = []float64{18, 15, 13, 12, 11, 11}
+
+// PdfStyle is the look of a custom-style span or fenced div in pdf documents,
+// and in html documents through the default stylesheet.
+type PdfStyle struct {
+	// Color is a #rrggbb color
+	Color  string  `json:"color,omitempty"`
+	Size   float64 `json:"size,omitempty"`
+	Bold   bool    `json:"bold,omitempty"`
+	Italic bool    `json:"italic,omitempty"`
+	// Align is left, center or right, for divs only
+	Align string `json:"align,omitempty"`
+}
+
+// PdfOptions are the options of MarkdownToPdf.
+type PdfOptions struct {
+	// Header is drawn at the top right of every page, next to the Logo,
+	// a PNG or JPEG image
+	Header string
+	Logo   []byte
+	// Footer is drawn at the bottom of every page, {page} and {pages} are
+	// replaced by the page number and the number of pages
+	Footer string
+	// Styles override DefaultPdfStyles
+	Styles map[string]PdfStyle
+}
+
+// DefaultPdfStyles are the styles of the custom-style names used in the
+// reference docs, and of the pandoc title block.
+var DefaultPdfStyles = map[string]PdfStyle{
+	"Danger":    {Color: "#c00000"},
+	"Warning":   {Color: "#c55a11"},
+	"Success":   {Color: "#548235"},
+	"Info":      {Color: "#2e75b6"},
+	"Secondary": {Color: "#7f7f7f"},
+	"Title":     {Size: 24, Bold: true, Align: "center"},
+	"Subtitle":  {Size: 16, Align: "center"},
+	"Author":    {Align: "center"},
+	"Date":      {Align: "center"},
+}
+
+var pdfLinkColor = [3]float64{0.02, 0.39, 0.76}
+
+// pdfFormat is the formatting of a run of text.
+type pdfFormat struct {
+	font  int
+	size  float64
+	color [3]float64
+	link  string
+}
+
+// pdfRun is text drawn with a single format.
+type pdfRun struct {
+	pdfFormat
+	text  []byte
+	width float64
+}
+
+type pdfWord struct {
+	runs  []pdfRun
+	width float64
+	// width of the space after the word
+	space float64
+	// a hard break follows the word
+	brk bool
+}
+
+type pdfLine struct {
+	words []pdfWord
+	width float64
+	size  float64
+}
+
+// pdfTextStyle is the base style of the text of a block.
+type pdfTextStyle struct {
+	bold   bool
+	italic bool
+	size   float64
+	color  [3]float64
+}
+
+type pdfLink struct {
+	rect [4]float64
+	uri  string
+}
+
+type pdfPage struct {
+	content bytes.Buffer
+	links   []pdfLink
+}
+
+type pdfWriter struct {
+	styles map[string]PdfStyle
+	pages  []*pdfPage
+	page   *pdfPage
+	// top of the free space of the page
+	y float64
+}
+
+// MarkdownToPdf converts markdown to a pdf document, see parseMarkdown for
+// the syntax supported.
+func MarkdownToPdf(markdown string, options PdfOptions) ([]byte, error) {
+	w := &pdfWriter{styles: make(map[string]PdfStyle)}
+	for name, style := range DefaultPdfStyles {
+		w.styles[name] = style
+	}
+	for name, style := range options.Styles {
+		w.styles[name] = style
+	}
+
+	var logo *pdfImage
+	if len(options.Logo) > 0 {
+		var err error
+		if logo, err = newPdfImage(options.Logo); err != nil {
+			return nil, fmt.Errorf("invalid logo: %v", err)
+		}
+	}
+
+	w.newPage()
+	w.writeBlocks(parseMarkdown(markdown))
+	for i, page := range w.pages {
+		w.page = page
+		w.decorate(i+1, logo, options)
+	}
+	return w.output(logo)
+}
+
+func (w *pdfWriter) newPage() {
+	w.page = &pdfPage{}
+	w.pages = append(w.pages, w.page)
+	w.y = pdfPageHeight - pdfMarginTop
+}
+
+// ensure starts a new page unless height fits in the current one.
+func (w *pdfWriter) ensure(height float64) {
+	if w.y-height < pdfMarginBottom && w.y < pdfPageHeight-pdfMarginTop {
+		w.newPage()
+	}
+}
+
+// space leaves a vertical space, but not at the top of a page.
+func (w *pdfWriter) space(height float64) {
+	if w.y < pdfPageHeight-pdfMarginTop {
+		w.y -= height
+	}
+}
+
+func (w *pdfWriter) writeBlocks(blocks []block) {
+	body := pdfTextStyle{size: pdfBodySize}
+	for _, b := range blocks {
+		switch b.Kind {
+		case blockHeading:
+			size := pdfHeadingSizes[b.Level-1]
+			lines := w.lines(b.Inlines, pdfTextStyle{bold: true, size: size}, pdfTextWidth)
+			// keep the heading with a few lines of what follows
+			w.ensure(size*1.2 + lineHeights(lines) + 3*pdfBodySize*pdfLineHeight)
+			w.space(size * 0.8)
+			w.drawLines(lines, pdfMarginX, pdfTextWidth, "")
+			w.y -= size * 0.3
+
+		case blockParagraph:
+			style, align := w.blockStyle(b.Style, body)
+			w.drawLines(w.lines(b.Inlines, style, pdfTextWidth), pdfMarginX, pdfTextWidth, align)
+			w.y -= 6
+
+		case blockListItem:
+			style, _ := w.blockStyle(b.Style, body)
+			indent := pdfListIndent * float64(b.Level+1)
+			marker := "•"
+			if b.Ordered {
+				marker = strconv.Itoa(b.Number) + "."
+			}
+			lines := w.lines(b.Inlines, style, pdfTextWidth-indent)
+			if len(lines) == 0 {
+				continue
+			}
+			w.ensure(lines[0].size * pdfLineHeight)
+			markerLine := w.lines([]inline{{Text: marker}}, style, pdfListIndent)
+			w.drawLine(markerLine[0], pdfMarginX+indent-pdfListIndent*0.75, w.y-lines[0].size*1.05, pdfListIndent, "")
+			w.drawLines(lines, pdfMarginX+indent, pdfTextWidth-indent, "")
+			w.y -= 2
+
+		case blockQuote:
+			style, _ := w.blockStyle(b.Style, body)
+			if b.Style == "" {
+				style.color = [3]float64{0.35, 0.35, 0.35}
+			}
+			lines := w.lines(b.Inlines, style, pdfTextWidth-pdfListIndent)
+			w.ensure(lineHeights(lines))
+			top := w.y
+			w.drawLines(lines, pdfMarginX+pdfListIndent, pdfTextWidth-pdfListIndent, "")
+			if w.y < top {
+				w.rule(pdfMarginX+4, top, pdfMarginX+4, w.y, 1.5, 0.75)
+			}
+			w.y -= 6
+
+		case blockRule:
+			w.ensure(12)
+			w.y -= 6
+			w.rule(pdfMarginX, w.y, pdfMarginX+pdfTextWidth, w.y, 0.5, 0.6)
+			w.y -= 6
+
+		case blockTable:
+			w.table(b.Table)
+			w.y -= 8
+		}
+	}
+}
+
+// blockStyle returns the text style and the alignment of a custom style.
+func (w *pdfWriter) blockStyle(name string, base pdfTextStyle) (pdfTextStyle, string) {
+	style, ok := w.styles[name]
+	if !ok {
+		return base, ""
+	}
+	return applyPdfStyle(base, style), style.Align
+}
+
+func applyPdfStyle(base pdfTextStyle, style PdfStyle) pdfTextStyle {
+	if style.Color != "" {
+		base.color = parseColor(style.Color)
+	}
+	if style.Size > 0 {
+		base.size = style.Size
+	}
+	base.bold = base.bold || style.Bold
+	base.italic = base.italic || style.Italic
+	return base
+}
+
+// parseColor parses a #rrggbb color, black when invalid.
+func parseColor(s string) [3]float64 {
+	var color [3]float64
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(s, "#")) != 6 {
+		return color
+	}
+	for i := range color {
+		color[i] = float64((v>>(16-8*i))&0xFF) / 255
+	}
+	return color
+}
+
+// words splits inlines in words, the units lines are broken at.
+func (w *pdfWriter) words(inlines []inline, base pdfTextStyle) []pdfWord {
+	words := make([]pdfWord, 0)
+	var word pdfWord
+	finish := func(space float64) {
+		if len(word.runs) > 0 {
+			word.space = space
+			words = append(words, word)
+		}
+		word = pdfWord{}
+	}
+
+	for _, in := range inlines {
+		in.Link = safeLink(in.Link)
+		style := base
+		style.bold = style.bold || in.Bold
+		style.italic = style.italic || in.Italic
+		if s, ok := w.styles[in.Style]; ok {
+			style = applyPdfStyle(style, s)
+		}
+		format := pdfFormat{size: style.size, color: style.color, link: in.Link}
+		switch {
+		case in.Code:
+			format.font = fontMono
+		case style.bold && style.italic:
+			format.font = fontBoldItalic
+		case style.bold:
+			format.font = fontBold
+		case style.italic:
+			format.font = fontItalic
+		}
+		if in.Link != "" && in.Style == "" {
+			format.color = pdfLinkColor
+		}
+
+		if in.Break {
+			finish(0)
+			if len(words) == 0 {
+				words = append(words, pdfWord{})
+			}
+			words[len(words)-1].brk = true
+			continue
+		}
+		for _, r := range in.Text {
+			if r == ' ' || r == '\n' {
+				finish(float64(glyphWidth(format.font, ' ')) * format.size / 1000)
+				continue
+			}
+			symbol, b, ok := encodeRune(r)
+			if !ok {
+				continue
+			}
+			f := format
+			if symbol {
+				f.font = fontSymbol
+			}
+			if n := len(word.runs); n == 0 || word.runs[n-1].pdfFormat != f {
+				word.runs = append(word.runs, pdfRun{pdfFormat: f})
+			}
+			width := float64(glyphWidth(f.font, b)) * f.size / 1000
+			run := &word.runs[len(word.runs)-1]
+			run.text = append(run.text, b)
+			run.width += width
+			word.width += width
+		}
+	}
+	finish(0)
+	return words
+}
+
+// lines breaks the text of inlines in lines not wider than width.
+func (w *pdfWriter) lines(inlines []inline, base pdfTextStyle, width float64) []pdfLine {
+	lines := make([]pdfLine, 0)
+	var line pdfLine
+	for _, word := range w.words(inlines, base) {
+		if n := len(line.words); n > 0 && line.width+line.words[n-1].space+word.width > width {
+			lines = append(lines, line)
+			line = pdfLine{}
+		}
+		if n := len(line.words); n > 0 {
+			line.width += line.words[n-1].space
+		}
+		line.words = append(line.words, word)
+		line.width += word.width
+		for _, run := range word.runs {
+			line.size = max(line.size, run.size)
+		}
+		if word.brk {
+			lines = append(lines, line)
+			line = pdfLine{}
+		}
+	}
+	if len(line.words) > 0 {
+		lines = append(lines, line)
+	}
+	for i := range lines {
+		if lines[i].size == 0 {
+			lines[i].size = base.size
+		}
+	}
+	return lines
+}
+
+func lineHeights(lines []pdfLine) float64 {
+	height := 0.0
+	for _, line := range lines {
+		height += line.size * pdfLineHeight
+	}
+	return height
+}
+
+// drawLines draws lines from the top of the free space, breaking pages.
+func (w *pdfWriter) drawLines(lines []pdfLine, x float64, width float64, align string) {
+	for _, line := range lines {
+		height := line.size * pdfLineHeight
+		w.ensure(height)
+		w.drawLine(line, x, w.y-line.size*1.05, width, align)
+		w.y -= height
+	}
+}
+
+// drawLine draws a line, joining the words of the same format in a single
+// text so that readers copy the spaces too.
+func (w *pdfWriter) drawLine(line pdfLine, x float64, baseline float64, width float64, align string) {
+	switch align {
+	case "center":
+		x += (width - line.width) / 2
+	case "right":
+		x += width - line.width
+	}
+
+	var text *pdfRun
+	var start, space float64
+	draw := func() {
+		if text == nil {
+			return
+		}
+		fmt.Fprintf(&w.page.content, "BT /F%d %.2f Tf %.3f %.3f %.3f rg %.2f %.2f Td (%s) Tj ET\n",
+			text.font+1, text.size, text.color[0], text.color[1], text.color[2], start, baseline, escapePdf(text.text))
+		if text.link != "" {
+			w.page.links = append(w.page.links, pdfLink{
+				rect: [4]float64{start, baseline - text.size*0.25, start + text.width, baseline + text.size*0.85},
+				uri:  text.link,
+			})
+		}
+		text = nil
+	}
+	for _, word := range line.words {
+		for i, run := range word.runs {
+			switch {
+			case text != nil && text.pdfFormat == run.pdfFormat && i == 0:
+				text.text = append(text.text, ' ')
+				text.width += space
+				fallthrough
+			case text != nil && text.pdfFormat == run.pdfFormat:
+				text.text = append(text.text, run.text...)
+				text.width += run.width
+			default:
+				draw()
+				text = &pdfRun{pdfFormat: run.pdfFormat, text: append([]byte{}, run.text...), width: run.width}
+				start = x
+			}
+			x += run.width
+		}
+		space = word.space
+		x += word.space
+	}
+	draw()
+}
+
+// rule strokes a line of the given width and gray level.
+func (w *pdfWriter) rule(x1, y1, x2, y2, width, gray float64) {
+	fmt.Fprintf(&w.page.content, "%.3f G %.2f w %.2f %.2f m %.2f %.2f l S\n", gray, width, x1, y1, x2, y2)
+}
+
+// table draws a table whose column widths are proportional to the dashes
+// of the separator line, repeating the header row on every page.
+func (w *pdfWriter) table(t *table) {
+	total := 0
+	for _, width := range t.Widths {
+		total += width
+	}
+	widths := make([]float64, len(t.Widths))
+	for i, width := range t.Widths {
+		widths[i] = pdfTextWidth * float64(width) / float64(total)
+	}
+
+	layoutRow := func(cells [][]inline, style pdfTextStyle) ([][]pdfLine, float64) {
+		rows := make([][]pdfLine, len(widths))
+		height := 0.0
+		for i := range widths {
+			if i < len(cells) {
+				rows[i] = w.lines(cells[i], style, widths[i]-2*pdfCellPadding)
+			}
+			height = max(height, lineHeights(rows[i]))
+		}
+		return rows, height + 2*pdfCellPadding
+	}
+	drawRow := func(rows [][]pdfLine, height float64, header bool) {
+		if header {
+			fmt.Fprintf(&w.page.content, "0.92 g %.2f %.2f %.2f %.2f re f\n", pdfMarginX, w.y-height, pdfTextWidth, height)
+		}
+		x := pdfMarginX
+		for i, lines := range rows {
+			y := w.y - pdfCellPadding
+			for _, line := range lines {
+				w.drawLine(line, x+pdfCellPadding, y-line.size*1.05, widths[i]-2*pdfCellPadding, t.Aligns[i])
+				y -= line.size * pdfLineHeight
+			}
+			x += widths[i]
+		}
+		w.y -= height
+		if header {
+			w.rule(pdfMarginX, w.y, pdfMarginX+pdfTextWidth, w.y, 0.8, 0)
+		} else {
+			w.rule(pdfMarginX, w.y, pdfMarginX+pdfTextWidth, w.y, 0.3, 0.7)
+		}
+	}
+
+	hasHeader := false
+	for _, cell := range t.Header {
+		if len(cell) > 0 {
+			hasHeader = true
+		}
+	}
+	header, headerHeight := layoutRow(t.Header, pdfTextStyle{bold: true, size: pdfTableSize})
+	if !hasHeader {
+		headerHeight = 0
+	}
+	start := func() {
+		w.rule(pdfMarginX, w.y, pdfMarginX+pdfTextWidth, w.y, 0.8, 0)
+		if hasHeader {
+			drawRow(header, headerHeight, true)
+		}
+	}
+
+	for i, cells := range t.Rows {
+		rows, height := layoutRow(cells, pdfTextStyle{size: pdfTableSize})
+		if i == 0 {
+			w.ensure(headerHeight + height)
+			start()
+		} else if w.y-height < pdfMarginBottom {
+			w.rule(pdfMarginX, w.y, pdfMarginX+pdfTextWidth, w.y, 0.8, 0)
+			w.newPage()
+			start()
+		}
+		drawRow(rows, height, false)
+	}
+	if len(t.Rows) == 0 {
+		w.ensure(headerHeight)
+		start()
+	}
+	w.rule(pdfMarginX, w.y, pdfMarginX+pdfTextWidth, w.y, 0.8, 0)
+}
+
+// decorate draws the logo, the header and the footer of a page.
+func (w *pdfWriter) decorate(page int, logo *pdfImage, options PdfOptions) {
+	style := pdfTextStyle{size: pdfHeaderSize, color: [3]float64{0.4, 0.4, 0.4}}
+	top := pdfPageHeight - pdfMarginTop + 14
+
+	if logo != nil {
+		height := pdfLogoHeight
+		width := height * float64(logo.width) / float64(logo.height)
+		if width > pdfLogoMaxWidth {
+			width = pdfLogoMaxWidth
+			height = width * float64(logo.height) / float64(logo.width)
+		}
+		fmt.Fprintf(&w.page.content, "q %.2f 0 0 %.2f %.2f %.2f cm /Im1 Do Q\n", width, height, pdfMarginX, top+4)
+	}
+	if options.Header != "" {
+		lines := w.lines(parseInlines(options.Header), style, pdfTextWidth/2)
+		for i, line := range lines {
+			baseline := top + 6 + float64(len(lines)-1-i)*pdfHeaderSize*pdfLineHeight
+			w.drawLine(line, pdfMarginX+pdfTextWidth/2, baseline, pdfTextWidth/2, "right")
+		}
+	}
+	if logo != nil || options.Header != "" {
+		w.rule(pdfMarginX, top, pdfMarginX+pdfTextWidth, top, 0.5, 0.6)
+	}
+
+	if options.Footer != "" {
+		footer := strings.NewReplacer("{page}", strconv.Itoa(page), "{pages}", strconv.Itoa(len(w.pages))).Replace(options.Footer)
+		baseline := pdfMarginBottom - 24
+		for _, line := range w.lines(parseInlines(footer), style, pdfTextWidth) {
+			w.drawLine(line, pdfMarginX, baseline, pdfTextWidth, "center")
+			baseline -= pdfHeaderSize * pdfLineHeight
+		}
+	}
+}
+
+func escapePdf(text []byte) string {
+	var b strings.Builder
+	for _, c := range text {
+		switch c {
+		case '\\', '(', ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// pdfImage is an image as RGB samples, compressed.
+type pdfImage struct {
+	width  int
+	height int
+	data   []byte
+}
+
+// newPdfImage decodes a PNG or JPEG image, blending transparency with white.
+func newPdfImage(data []byte) (*pdfImage, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	var samples bytes.Buffer
+	z := zlib.NewWriter(&samples)
+	row := make([]byte, 0, bounds.Dx()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xFFFF - a
+			row = append(row, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+		if _, err := z.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return &pdfImage{width: bounds.Dx(), height: bounds.Dy(), data: samples.Bytes()}, nil
+}
+
+// output writes the pdf file: catalog, page tree, fonts, logo, then pages
+// and their contents.
+func (w *pdfWriter) output(logo *pdfImage) ([]byte, error) {
+	var buf bytes.Buffer
+	offsets := make([]int, 0)
+	begin := func() int {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		return len(offsets)
+	}
+	end := func() {
+		buf.WriteString("\nendobj\n")
+	}
+	stream := func(dict string, data []byte) {
+		fmt.Fprintf(&buf, "<< %s /Length %d >>\nstream\n", dict, len(data))
+		buf.Write(data)
+		buf.WriteString("\nendstream")
+	}
+
+	firstPage := 3 + len(pdfFonts)
+	if logo != nil {
+		firstPage++
+	}
+	kids := make([]string, len(w.pages))
+	for i := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	begin()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>")
+	end()
+	begin()
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages))
+	end()
+
+	var resources strings.Builder
+	resources.WriteString("<< /Font <<")
+	for i, font := range pdfFonts {
+		n := begin()
+		if i == fontSymbol {
+			fmt.Fprintf(&buf, "<< /Type /Font /Subtype /Type1 /BaseFont /%s >>", font)
+		} else {
+			fmt.Fprintf(&buf, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font)
+		}
+		end()
+		fmt.Fprintf(&resources, " /F%d %d 0 R", i+1, n)
+	}
+	resources.WriteString(" >>")
+	if logo != nil {
+		n := begin()
+		stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode",
+			logo.width, logo.height), logo.data)
+		end()
+		fmt.Fprintf(&resources, " /XObject << /Im1 %d 0 R >>", n)
+	}
+	resources.WriteString(" >>")
+
+	for _, page := range w.pages {
+		n := begin()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R",
+			pdfPageWidth, pdfPageHeight, resources.String(), n+1)
+		if len(page.links) > 0 {
+			buf.WriteString(" /Annots [")
+			for _, link := range page.links {
+				fmt.Fprintf(&buf, " << /Type /Annot /Subtype /Link /Rect [%.2f %.2f %.2f %.2f] /Border [0 0 0] /A << /S /URI /URI (%s) >> >>",
+					link.rect[0], link.rect[1], link.rect[2], link.rect[3], escapePdf([]byte(link.uri)))
+			}
+			buf.WriteString(" ]")
+		}
+		buf.WriteString(" >>")
+		end()
+
+		var content bytes.Buffer
+		z := zlib.NewWriter(&content)
+		if _, err := z.Write(page.content.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := z.Close(); err != nil {
+			return nil, err
+		}
+		begin()
+		stream("/Filter /FlateDecode", content.Bytes())
+		end()
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes(), nil
+}
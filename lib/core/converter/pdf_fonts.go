@@ -0,0 +1,131 @@
+package converter
+
+// The pdf documents use the standard 14 fonts, which every reader provides:
+// Helvetica for the text, Courier for code and ZapfDingbats for the check
+// marks the templates are fond of. Text is encoded as WinAnsiEncoding.
+
+const (
+	fontRegular = iota
+	fontBold
+	fontItalic
+	fontBoldItalic
+	fontMono
+	fontSymbol
+)
+
+var pdfFonts = []string{"Helvetica", "Helvetica-Bold", "Helvetica-Oblique", "Helvetica-BoldOblique", "Courier", "ZapfDingbats"}
+
+// widths of the characters from space to tilde, in thousandths of the size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// widths of the WinAnsi characters above tilde that are not accented letters
+var winAnsiWidths = map[byte]int{
+	0x80: 556, 0x85: 1000, 0x91: 222, 0x92: 222, 0x93: 333, 0x94: 333, 0x95: 350,
+	0x96: 556, 0x97: 1000, 0x99: 1000, 0xA0: 278, 0xA9: 737, 0xAB: 556, 0xAE: 737,
+	0xB0: 400, 0xB7: 278, 0xBB: 556, 0xD7: 584, 0xDF: 611, 0xE6: 889, 0xC6: 1000,
+}
+
+var winAnsiRunes = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+var dingbatRunes = map[rune]byte{
+	'✓': 0x33, '✔': 0x34, '✕': 0x35, '✖': 0x36, '✗': 0x37, '✘': 0x38,
+	'★': 0x48, '●': 0x6C, '■': 0x6E, '▲': 0x73, '▼': 0x74, '◆': 0x75,
+}
+
+var dingbatWidths = map[byte]int{
+	0x33: 755, 0x34: 846, 0x35: 762, 0x36: 761, 0x37: 571, 0x38: 677,
+	0x48: 816, 0x6C: 791, 0x6E: 761, 0x73: 892, 0x74: 892, 0x75: 788,
+}
+
+// emoji without a glyph in the standard fonts, and their replacements
+var runeFallbacks = map[rune]rune{
+	'✅': '✔', '❌': '✖', '❎': '✖', '➖': '–', '➕': '+', '⚠': '!', '❗': '!',
+	'❓': '?', '⭐': '★', '🔴': '●', '🟢': '●', '🟡': '●',
+}
+
+// accented letters of Latin-1, measured as their base letter
+var latin1Letters = func() map[byte]byte {
+	letters := make(map[byte]byte)
+	for base, accented := range map[byte]string{
+		'A': "ÀÁÂÃÄÅ", 'C': "Ç", 'E': "ÈÉÊË", 'I': "ÌÍÎÏ", 'N': "Ñ", 'O': "ÒÓÔÕÖØ",
+		'U': "ÙÚÛÜ", 'Y': "Ý", 'a': "àáâãäå", 'c': "ç", 'e': "èéêë", 'i': "ìíîï",
+		'n': "ñ", 'o': "òóôõöø", 'u': "ùúûü", 'y': "ýÿ",
+	} {
+		for _, r := range accented {
+			letters[byte(r)] = base
+		}
+	}
+	return letters
+}()
+
+// encodeRune returns the byte of a rune and whether it is drawn with the
+// symbol font; ok is false for the runes that are not drawn at all.
+func encodeRune(r rune) (symbol bool, b byte, ok bool) {
+	if fallback, found := runeFallbacks[r]; found {
+		r = fallback
+	}
+	switch {
+	case r == '\t':
+		return false, ' ', true
+	case r < 0x20 || r == 0xFE0F || r == 0x200D:
+		// controls, emoji variation selectors and joiners
+		return false, 0, false
+	case r < 0x7F || (r >= 0xA0 && r <= 0xFF):
+		return false, byte(r), true
+	}
+	if b, found := winAnsiRunes[r]; found {
+		return false, b, true
+	}
+	if b, found := dingbatRunes[r]; found {
+		return true, b, true
+	}
+	return false, '?', true
+}
+
+// glyphWidth returns the width of a character, in thousandths of the size.
+func glyphWidth(font int, b byte) int {
+	switch font {
+	case fontMono:
+		return 600
+	case fontSymbol:
+		if w, ok := dingbatWidths[b]; ok {
+			return w
+		}
+		return 800
+	}
+	widths := &helveticaWidths
+	if font == fontBold || font == fontBoldItalic {
+		widths = &helveticaBoldWidths
+	}
+	if base, ok := latin1Letters[b]; ok {
+		b = base
+	}
+	if b >= 0x20 && b < 0x7F {
+		return widths[b-0x20]
+	}
+	if w, ok := winAnsiWidths[b]; ok {
+		return w
+	}
+	return 556
+}
@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var pdfStreamRegexp = regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`)
+
+// pdfContent returns the uncompressed content streams of a pdf.
+func pdfContent(t *testing.T, data []byte) string {
+	t.Helper()
+	var content strings.Builder
+	for _, m := range pdfStreamRegexp.FindAllSubmatch(data, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			// images are not deflated
+			continue
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("cannot inflate a stream: %v", err)
+		}
+		content.Write(b)
+	}
+	return content.String()
+}
+
+func TestMarkdownToPdf(t *testing.T) {
+	var md strings.Builder
+	md.WriteString("# Title (1)\n\n")
+	for range 120 {
+		md.WriteString("A paragraph long enough to fill the pages of the report.\n\n")
+	}
+	out, err := MarkdownToPdf(md.String(), PdfOptions{Header: "Head", Footer: "Page {page} of {pages}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-")) || !bytes.HasSuffix(bytes.TrimSpace(out), []byte("%%EOF")) {
+		t.Fatalf("not a pdf")
+	}
+	pages := bytes.Count(out, []byte("/Type /Page "))
+	if pages < 2 {
+		t.Fatalf("got %d pages, want the text to overflow", pages)
+	}
+
+	content := pdfContent(t, out)
+	for _, want := range []string{`(Title \(1\))`, `(Head)`, `(Page 1 of `, `(Page 2 of `} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %s in the content", want)
+		}
+	}
+	if strings.Count(content, "(Head)") != pages {
+		t.Errorf("the header is not on every page")
+	}
+}
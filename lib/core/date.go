@@ -0,0 +1,232 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gterranova/go-bexpr"
+)
+
+const (
+	DATE_FORMAT = "2006-01-02"
+	DATE_TODAY  = "today"
+)
+
+// Date holds a calendar day as an ISO 8601 string. In Project.Tags the date
+// is exposed as the number of days since 1970-01-01, so that expressions
+// can compare dates and add or subtract days.
+type Date struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Value      string `json:"value" bexpr:"value"`
+	Disabled   bool   `json:"disabled,omitempty"`
+	Default    string `json:"default,omitempty"`
+	Min        string `json:"min,omitempty"`
+	Max        string `json:"max,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Condition  string `json:"condition,omitempty"`
+	DisabledOn string `json:"disabled_on,omitempty"`
+	InfoUrl    string `json:"info_url,omitempty"`
+
+	valueEvaluator    *Evaluator `json:"-" bexpr:"-"`
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
+}
+
+// Today returns the current day number.
+func Today() int64 {
+	return DateToDay(time.Now())
+}
+
+// DateToDay returns the number of days between 1970-01-01 and the date.
+func DateToDay(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+// DayToDate formats a day number as an ISO 8601 date.
+func DayToDate(day int64) string {
+	return time.Unix(day*86400, 0).UTC().Format(DATE_FORMAT)
+}
+
+// ParseDay parses an ISO 8601 date, or "today", into a day number.
+func ParseDay(s string) (int64, error) {
+	if s == DATE_TODAY {
+		return Today(), nil
+	}
+	t, err := time.Parse(DATE_FORMAT, s)
+	if err != nil {
+		// date pickers may send a full timestamp
+		if t, err = time.Parse(time.RFC3339, s); err != nil {
+			return 0, fmt.Errorf("invalid date %q", s)
+		}
+	}
+	return DateToDay(t), nil
+}
+
+func (feature *Date) Validate(tag string, i any) (changed bool, err error) {
+	var valueChanged bool
+
+	if changed, err = feature.EvaluateDisabledOn(i); err != nil {
+		return
+	}
+	valueChanged, err = feature.EvaluateCondition(i)
+	return changed || valueChanged, err
+}
+
+func (feature *Date) EvaluateCondition(i any) (changed bool, err error) {
+	if !feature.Disabled && feature.Tag != "" && feature.valueEvaluator != nil {
+		result, err := feature.valueEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, err)
+		}
+		// expressions over missing dates leave the date empty
+		b := ""
+		if day, coerce_err := bexpr.CoerceInt64(result); coerce_err == nil {
+			b = DayToDate(day)
+		}
+		changed = feature.Value != b
+		feature.Value = b
+	}
+	return
+}
+
+func (feature *Date) EvaluateDisabledOn(i any) (changed bool, err error) {
+	if len(feature.DisabledOn) > 0 {
+		result, err := feature.disabledEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
+		}
+		b, _ := bexpr.CoerceBool(result)
+		changed = feature.Disabled != b
+		feature.Disabled = b
+	}
+	return
+}
+
+func (feature *Date) ApplyDefaults() error {
+	// apply defaults
+	if feature.Condition != "" {
+		eval, err := NewEvaluator(feature.Condition)
+		if err != nil {
+			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
+		} else {
+			feature.valueEvaluator = eval
+		}
+	}
+
+	if feature.DisabledOn != "" {
+		eval, err := NewEvaluator(feature.DisabledOn)
+		if err != nil {
+			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
+		} else {
+			feature.disabledEvaluator = eval
+		}
+	}
+
+	return feature.SetValue(feature.Default)
+}
+
+func (feature *Date) Set(tag string, value any) error {
+	if feature.Tag == tag {
+		return feature.SetValue(value)
+	}
+	return nil
+}
+
+func (feature *Date) ApplicableFeatures() []Feature {
+	f := make([]Feature, 0)
+	if !feature.Disabled && feature.Value != "" {
+		f = append(f, feature)
+	}
+	return f
+}
+
+func (feature *Date) GetChildren() []Feature {
+	return []Feature{}
+}
+
+func (feature *Date) GetValue() any {
+	return feature.Value
+}
+
+// GetTagValue implements FeatureWithTagValue.
+func (feature *Date) GetTagValue() any {
+	day, _ := ParseDay(feature.Value)
+	return day
+}
+
+func (feature *Date) SetValue(value any) error {
+	var day int64
+	var err error
+
+	switch v := value.(type) {
+	case nil:
+		feature.Value = ""
+		return nil
+	case string:
+		if v == "" {
+			feature.Value = ""
+			return nil
+		}
+		if day, err = ParseDay(v); err != nil {
+			return err
+		}
+	case time.Time:
+		day = DateToDay(v)
+	case int64:
+		day = v
+	case int:
+		day = int64(v)
+	case float64:
+		day = int64(v)
+	default:
+		return fmt.Errorf("cannot convert %v (%T) to date", value, value)
+	}
+
+	if feature.Min != "" {
+		if min, err := ParseDay(feature.Min); err == nil && day < min {
+			return fmt.Errorf("date %s is before %s", DayToDate(day), DayToDate(min))
+		}
+	}
+	if feature.Max != "" {
+		if max, err := ParseDay(feature.Max); err == nil && day > max {
+			return fmt.Errorf("date %s is after %s", DayToDate(day), DayToDate(max))
+		}
+	}
+	feature.Value = DayToDate(day)
+	return nil
+}
+
+func (feature *Date) GetTag() string {
+	return feature.Tag
+}
+func (feature *Date) SetTag(tag string) {
+	feature.Tag = tag
+}
+func (feature *Date) IsDisabled() bool {
+	return feature.Disabled
+}
+
+func (feature *Date) GetType() string {
+	return feature.Type
+}
+func (feature *Date) GetTitle() string {
+	return feature.Title
+}
+
+// InfoUrl implements FeatureWithInfoUrl.
+func (feature *Date) GetInfoUrl() string {
+	return feature.InfoUrl
+}
+
+func (feature *Date) GetCondition() string {
+	return feature.Condition
+}
+
+func (feature *Date) GetDisabledOn() string {
+	return feature.DisabledOn
+}
+
+func (p *Date) MarshalJSON() ([]byte, error) {
+	marshaller := &DateFormly{p}
+	return marshaller.MarshalJSON()
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"encoding/json"
+)
+
+type DateFormly struct {
+	*Date
+}
+
+func (feature *DateFormly) MarshalJSON() ([]byte, error) {
+	type jsonFeature struct {
+		Type     string `json:"type"`
+		Format   string `json:"format"`
+		Title    string `json:"title"`
+		Value    string `json:"value,omitempty"`
+		Disabled bool   `json:"disabled,omitempty"`
+		Default  string `json:"default,omitempty"`
+		Widget   struct {
+			FormlyConfig map[string]any `json:"formlyConfig,omitempty"`
+		} `json:"widget,omitempty"`
+	}
+	foo := jsonFeature{
+		Type:     "string",
+		Format:   "date",
+		Title:    feature.Title,
+		Value:    feature.Value,
+		Disabled: feature.Disabled,
+	}
+
+	props := map[string]any{
+		"type": "date",
+	}
+	if day, err := ParseDay(feature.Default); err == nil {
+		foo.Default = DayToDate(day)
+	}
+	if day, err := ParseDay(feature.Min); err == nil {
+		props["min"] = DayToDate(day)
+	}
+	if day, err := ParseDay(feature.Max); err == nil {
+		props["max"] = DayToDate(day)
+	}
+	if feature.InfoUrl != "" {
+		props["info_url"] = feature.InfoUrl
+	}
+	foo.Widget.FormlyConfig = map[string]any{
+		"props": props,
+	}
+
+	valueBytes, err := json.Marshal(foo)
+	if err != nil {
+		return nil, err
+	}
+
+	return valueBytes, nil
+}
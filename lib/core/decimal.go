@@ -0,0 +1,225 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gterranova/go-bexpr"
+	"github.com/shopspring/decimal"
+)
+
+// Decimal is a number with a fixed number of decimal places, for amounts,
+// percentages and measures. The value is exposed as a json.Number holding
+// its fixed-point representation, so that it reaches the forms, the
+// exported data and the templates without float rounding. A decimal without
+// a default is empty, and its bounds apply once it is answered. Without a
+// precision the values are kept as entered, with no rounding.
+type Decimal struct {
+	Type       string           `json:"type"`
+	Title      string           `json:"title"`
+	Value      decimal.Decimal  `json:"value" bexpr:"value"`
+	Disabled   bool             `json:"disabled,omitempty"`
+	Default    *decimal.Decimal `json:"default,omitempty"`
+	Precision  *int32           `json:"precision,omitempty"`
+	Unit       string           `json:"unit,omitempty"`
+	Min        *decimal.Decimal `json:"min,omitempty"`
+	Max        *decimal.Decimal `json:"max,omitempty"`
+	Step       *decimal.Decimal `json:"step,omitempty"`
+	Tag        string           `json:"tag,omitempty"`
+	Condition  string           `json:"condition,omitempty"`
+	DisabledOn string           `json:"disabled_on,omitempty"`
+	InfoUrl    string           `json:"info_url,omitempty"`
+
+	// no value was set, Value is zero
+	empty bool `json:"-" bexpr:"-"`
+
+	valueEvaluator    *Evaluator `json:"-" bexpr:"-"`
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
+}
+
+func (feature *Decimal) Validate(tag string, i any) (changed bool, err error) {
+	var valueChanged bool
+
+	if changed, err = feature.EvaluateDisabledOn(i); err != nil {
+		return
+	}
+	valueChanged, err = feature.EvaluateCondition(i)
+	return changed || valueChanged, err
+}
+
+func (feature *Decimal) EvaluateCondition(i any) (changed bool, err error) {
+	if !feature.Disabled && feature.Tag != "" && feature.valueEvaluator != nil {
+		result, err := feature.valueEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, err)
+		}
+		b, _ := ParseDecimal(result)
+		b = feature.round(b)
+		changed = feature.empty || !feature.Value.Equal(b)
+		feature.Value, feature.empty = b, false
+	}
+	return
+}
+
+func (feature *Decimal) EvaluateDisabledOn(i any) (changed bool, err error) {
+	if len(feature.DisabledOn) > 0 {
+		result, err := feature.disabledEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
+		}
+		b, _ := bexpr.CoerceBool(result)
+		changed = feature.Disabled != b
+		feature.Disabled = b
+	}
+	return
+}
+
+func (feature *Decimal) ApplyDefaults() error {
+	// apply defaults
+	if feature.Condition != "" {
+		eval, err := NewEvaluator(feature.Condition)
+		if err != nil {
+			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
+		} else {
+			feature.valueEvaluator = eval
+		}
+	}
+
+	if feature.DisabledOn != "" {
+		eval, err := NewEvaluator(feature.DisabledOn)
+		if err != nil {
+			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
+		} else {
+			feature.disabledEvaluator = eval
+		}
+	}
+
+	if feature.Default == nil {
+		return feature.SetValue(nil)
+	}
+	return feature.SetValue(*feature.Default)
+}
+
+func (feature *Decimal) Set(tag string, value any) error {
+	if feature.Tag == tag {
+		return feature.SetValue(value)
+	}
+	return nil
+}
+
+func (feature *Decimal) ApplicableFeatures() []Feature {
+	f := make([]Feature, 0)
+	if !feature.Disabled {
+		f = append(f, feature)
+	}
+	return f
+}
+
+func (feature *Decimal) GetChildren() []Feature {
+	return []Feature{}
+}
+
+func (feature *Decimal) GetValue() any {
+	if feature.empty {
+		return nil
+	}
+	return json.Number(feature.format(feature.Value))
+}
+
+// round rounds d to the precision, if any.
+func (feature *Decimal) round(d decimal.Decimal) decimal.Decimal {
+	if feature.Precision == nil {
+		return d
+	}
+	return d.Round(*feature.Precision)
+}
+
+// format writes d with the decimal places of the precision, if any.
+func (feature *Decimal) format(d decimal.Decimal) string {
+	if feature.Precision == nil {
+		return d.String()
+	}
+	return d.StringFixed(*feature.Precision)
+}
+
+// ParseDecimal converts the values found in forms, exported data and
+// expression results to a decimal.
+func ParseDecimal(value any) (decimal.Decimal, error) {
+	switch v := value.(type) {
+	case nil:
+		return decimal.Zero, nil
+	case decimal.Decimal:
+		return v, nil
+	case *decimal.Decimal:
+		return *v, nil
+	case json.Number:
+		return decimal.NewFromString(string(v))
+	case string:
+		if v == "" {
+			return decimal.Zero, nil
+		}
+		return decimal.NewFromString(v)
+	case int64:
+		return decimal.NewFromInt(v), nil
+	case int:
+		return decimal.NewFromInt(int64(v)), nil
+	case float64:
+		return decimal.NewFromFloat(v), nil
+	}
+	return decimal.Zero, fmt.Errorf("cannot convert %v (%T) to decimal", value, value)
+}
+
+func (feature *Decimal) SetValue(value any) error {
+	if value == nil || value == "" {
+		feature.Value, feature.empty = decimal.Zero, true
+		return nil
+	}
+	d, err := ParseDecimal(value)
+	if err != nil {
+		return err
+	}
+	d = feature.round(d)
+	if feature.Min != nil && d.LessThan(*feature.Min) {
+		return fmt.Errorf("%s is lower than %s", d, feature.Min)
+	}
+	if feature.Max != nil && d.GreaterThan(*feature.Max) {
+		return fmt.Errorf("%s is greater than %s", d, feature.Max)
+	}
+	feature.Value, feature.empty = d, false
+	return nil
+}
+
+func (feature *Decimal) GetTag() string {
+	return feature.Tag
+}
+func (feature *Decimal) SetTag(tag string) {
+	feature.Tag = tag
+}
+func (feature *Decimal) IsDisabled() bool {
+	return feature.Disabled
+}
+
+func (feature *Decimal) GetType() string {
+	return feature.Type
+}
+func (feature *Decimal) GetTitle() string {
+	return feature.Title
+}
+
+// InfoUrl implements FeatureWithInfoUrl.
+func (feature *Decimal) GetInfoUrl() string {
+	return feature.InfoUrl
+}
+
+func (feature *Decimal) GetCondition() string {
+	return feature.Condition
+}
+
+func (feature *Decimal) GetDisabledOn() string {
+	return feature.DisabledOn
+}
+
+func (p *Decimal) MarshalJSON() ([]byte, error) {
+	marshaller := &DecimalFormly{p}
+	return marshaller.MarshalJSON()
+}
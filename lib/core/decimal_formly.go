@@ -0,0 +1,78 @@
+package core
+
+import (
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
+
+type DecimalFormly struct {
+	*Decimal
+}
+
+func (feature *DecimalFormly) MarshalJSON() ([]byte, error) {
+	type jsonFeature struct {
+		Type     string      `json:"type"`
+		Title    string      `json:"title"`
+		Value    json.Number `json:"value,omitempty"`
+		Disabled bool        `json:"disabled,omitempty"`
+		Default  json.Number `json:"default,omitempty"`
+		Minimum  json.Number `json:"minimum,omitempty"`
+		Maximum  json.Number `json:"maximum,omitempty"`
+		Widget   struct {
+			FormlyConfig map[string]any `json:"formlyConfig,omitempty"`
+		} `json:"widget,omitempty"`
+	}
+	fixed := func(d decimal.Decimal) json.Number {
+		return json.Number(feature.format(d))
+	}
+	foo := jsonFeature{
+		Type:     "number",
+		Title:    feature.Title,
+		Disabled: feature.Disabled,
+	}
+	if !feature.empty {
+		foo.Value = fixed(feature.Value)
+	}
+	if feature.Default != nil {
+		foo.Default = fixed(*feature.Default)
+	}
+
+	// the input step defaults to the smallest decimal allowed, any value
+	// without a precision
+	props := map[string]any{
+		"step": "any",
+	}
+	if feature.Step != nil {
+		props["step"] = json.Number(feature.Step.String())
+	} else if feature.Precision != nil {
+		props["step"] = json.Number(decimal.New(1, -*feature.Precision).String())
+	}
+	if feature.Min != nil {
+		foo.Minimum = fixed(*feature.Min)
+		props["min"] = foo.Minimum
+	}
+	if feature.Max != nil {
+		foo.Maximum = fixed(*feature.Max)
+		props["max"] = foo.Maximum
+	}
+	if feature.Unit != "" {
+		props["unit"] = feature.Unit
+		props["addonRight"] = map[string]any{
+			"text": feature.Unit,
+		}
+	}
+	if feature.InfoUrl != "" {
+		props["info_url"] = feature.InfoUrl
+	}
+	foo.Widget.FormlyConfig = map[string]any{
+		"props": props,
+	}
+
+	valueBytes, err := json.Marshal(foo)
+	if err != nil {
+		return nil, err
+	}
+
+	return valueBytes, nil
+}
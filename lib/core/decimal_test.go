@@ -0,0 +1,88 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const boundedDecimalConfig = `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+	"power": {"type": "decimal", "tag": "power", "title": "Power", "min": 1, "max": 100},
+	"big": {"type": "checkbox", "tag": "big", "title": "Big", "condition": "tags.power >= 50"}}}]}`
+
+func TestDecimalWithoutDefaultIsEmpty(t *testing.T) {
+	p := newTestProject(t, boundedDecimalConfig, nil)
+	power := p.GetFeature("power")
+	if v := power.GetValue(); v != nil {
+		t.Fatalf("power = %v, want empty", v)
+	}
+	// the sibling of the decimal is initialised, and reads it as missing
+	if big := p.GetFeature("big"); big == nil || big.GetValue() != false {
+		t.Fatalf("big = %v with power empty, want false", big)
+	}
+
+	mustSet(t, p, "power", json.Number("60"))
+	if p.Tags["big"] != true {
+		t.Errorf("big = %v with power 60, want true", p.Tags["big"])
+	}
+	if err := p.SetFeature("power", json.Number("0")); err == nil {
+		t.Errorf("power 0 is accepted below the minimum")
+	}
+	if err := p.ResetFeatures(); err != nil {
+		t.Fatalf("ResetFeatures: %v", err)
+	}
+	if v := power.GetValue(); v != nil {
+		t.Errorf("power = %v after a reset, want empty", v)
+	}
+}
+
+func TestDecimalDefaultOutOfBoundsFails(t *testing.T) {
+	l := memoryLoader{"config.json": []byte(`{"name": "T", "features": [{"type": "decimal", "tag": "power", "title": "Power", "min": 1, "default": 0}]}`)}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("a default below the minimum is accepted")
+		}
+	}()
+	NewProject(l)
+}
+
+func TestDecimalPrecision(t *testing.T) {
+	p := newTestProject(t, `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+		"exact": {"type": "decimal", "tag": "exact", "title": "Exact"},
+		"units": {"type": "decimal", "tag": "units", "title": "Units", "precision": 0},
+		"cents": {"type": "decimal", "tag": "cents", "title": "Cents", "precision": 2}}}]}`, nil)
+	for tag, want := range map[string]json.Number{"exact": "12.505", "units": "13", "cents": "12.51"} {
+		mustSet(t, p, tag, json.Number("12.505"))
+		if got := p.GetFeature(tag).GetValue(); got != want {
+			t.Errorf("%s = %v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestDecimalRoundTrip(t *testing.T) {
+	config := `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+		"amount": {"type": "decimal", "tag": "amount", "title": "Amount", "precision": 2},
+		"count": {"type": "number", "tag": "count", "title": "Count"}}}]}`
+	p := newTestProject(t, config, nil)
+	amount := json.Number("123456789012345678.25")
+	mustSet(t, p, "amount", amount)
+	mustSet(t, p, "count", int64(3))
+
+	data, err := json.Marshal(p.ExportData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var export ProjectExport
+	if err := UnmarshalValues(data, &export); err != nil {
+		t.Fatal(err)
+	}
+	other := newTestProject(t, config, nil)
+	if err := other.LoadProjectData(export); err != nil {
+		t.Fatal(err)
+	}
+	if got := other.GetFeature("amount").GetValue(); got != amount {
+		t.Errorf("amount = %v, want %v", got, amount)
+	}
+	if got := other.GetFeature("count").GetValue(); got != int64(3) {
+		t.Errorf("count = %v (%T), want 3", got, got)
+	}
+}
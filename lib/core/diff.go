@@ -0,0 +1,251 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Outcome is what a set of answers leads to: the answers themselves, the
+// tags and the text rendered by the templates of the current language.
+type Outcome struct {
+	// values of the features without a condition
+	Answers map[string]any `json:"answers"`
+	Tags    map[string]any `json:"tags"`
+	// rendered text by template name, templates failing to render excluded
+	Templates map[string]string `json:"templates"`
+}
+
+// ProjectDiff lists what changes from an outcome to another.
+type ProjectDiff struct {
+	Inputs []TagChange `json:"inputs"`
+	// tags changed other than the answers
+	Derived  []TagChange     `json:"derived"`
+	Sections []SectionChange `json:"sections"`
+}
+
+// SectionChange is a section of a rendered template, from a heading to the
+// next one, whose text differs.
+type SectionChange struct {
+	Template string `json:"template"`
+	// the heading, empty for the text before the first one
+	Title string `json:"title"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+	// lines removed (-) and added (+), see diffLines
+	Lines []string `json:"lines"`
+}
+
+// Empty tells whether the outcomes are the same.
+func (d *ProjectDiff) Empty() bool {
+	return len(d.Inputs) == 0 && len(d.Derived) == 0 && len(d.Sections) == 0
+}
+
+// Outcome returns the outcome of the current answers.
+func (p *Project) Outcome() *Outcome {
+	p.Validate("")
+	o := &Outcome{
+		Answers:   make(map[string]any),
+		Tags:      maps.Clone(p.Tags),
+		Templates: make(map[string]string),
+	}
+	for tag, value := range p.answers() {
+		if fe, ok := p.GetFeature(tag).(FeatureWithExpressions); ok && fe.GetCondition() != "" {
+			continue
+		}
+		o.Answers[tag] = value
+	}
+	for _, t := range p.Templates() {
+		if text, err := p.RenderText(t); err == nil {
+			o.Templates[t.Name] = text
+		}
+	}
+	return o
+}
+
+// OutcomeOf returns the outcome of answers given as by GetValue, keeping
+// the current ones.
+func (p *Project) OutcomeOf(values any) (*Outcome, error) {
+	var o *Outcome
+	err := p.keepingAnswers(func() error {
+		if err := p.ResetFeatures(); err != nil {
+			return err
+		}
+		if err := p.SetValue(values); err != nil {
+			return err
+		}
+		o = p.Outcome()
+		return nil
+	})
+	return o, err
+}
+
+// Diff compares the outcomes of two answer sets. The answers of the project
+// are restored afterwards, and the comparison is not recorded in the audit
+// log nor in the undo stacks.
+func (p *Project) Diff(a, b ProjectExport) (*ProjectDiff, error) {
+	var outcomes [2]*Outcome
+	err := p.keepingAnswers(func() error {
+		for i, export := range []ProjectExport{a, b} {
+			if err := p.ResetFeatures(); err != nil {
+				return err
+			}
+			if err := p.setProjectData(export); err != nil {
+				return err
+			}
+			outcomes[i] = p.Outcome()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return DiffOutcomes(outcomes[0], outcomes[1]), nil
+}
+
+// keepingAnswers runs fn, then restores the answers and the state of the
+// project as they were. The errors of the restore are returned with the one
+// of fn.
+func (p *Project) keepingAnswers(fn func() error) (err error) {
+	values := p.GetValue()
+	audit, undo, redo := p.audit, p.undo, p.redo
+	file, dirty, loading := p.ProjectFile, p.Dirty(), p.loading
+	p.loading = true
+	defer func() {
+		restoreErr := p.ResetFeatures()
+		if restoreErr == nil {
+			restoreErr = p.SetValue(values)
+		}
+		if _, validateErr := p.Validate(""); restoreErr == nil {
+			restoreErr = validateErr
+		}
+		if restoreErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to restore the answers: %v", restoreErr))
+		}
+		p.audit, p.undo, p.redo = audit, undo, redo
+		p.ProjectFile, p.loading = file, loading
+		p.SetDirty(dirty)
+	}()
+	return fn()
+}
+
+// DiffOutcomes returns the changes from the outcome a to b.
+func DiffOutcomes(a, b *Outcome) *ProjectDiff {
+	d := &ProjectDiff{
+		Inputs:   diffValues(a.Answers, b.Answers, nil),
+		Sections: make([]SectionChange, 0),
+	}
+	answered := make([]string, 0, len(d.Inputs))
+	for _, c := range d.Inputs {
+		answered = append(answered, c.Tag)
+	}
+	d.Derived = diffValues(a.Tags, b.Tags, answered)
+
+	names := slices.Collect(maps.Keys(a.Templates))
+	for name := range b.Templates {
+		if _, ok := a.Templates[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		d.Sections = append(d.Sections, diffSections(name, a.Templates[name], b.Templates[name])...)
+	}
+	return d
+}
+
+// diffValues returns the values changed from a to b by tag, but those of
+// the excluded tags and of their array items.
+func diffValues(a, b map[string]any, excluded []string) []TagChange {
+	tags := slices.Collect(maps.Keys(b))
+	for tag := range a {
+		if _, ok := b[tag]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	changes := make([]TagChange, 0)
+	for _, tag := range tags {
+		if slices.ContainsFunc(excluded, func(e string) bool {
+			return tag == e || strings.HasPrefix(tag, e+".")
+		}) {
+			continue
+		}
+		if !reflect.DeepEqual(a[tag], b[tag]) {
+			changes = append(changes, TagChange{Tag: tag, Old: a[tag], New: b[tag]})
+		}
+	}
+	return changes
+}
+
+// section is the text from a markdown heading to the next one.
+type section struct {
+	title string
+	text  string
+}
+
+func splitSections(text string) []section {
+	sections := make([]section, 0)
+	var lines []string
+	title := ""
+	flush := func() {
+		if body := strings.TrimSpace(strings.Join(lines, "\n")); body != "" || title != "" {
+			sections = append(sections, section{title: title, text: body})
+		}
+	}
+	for _, line := range snapshotLines(text) {
+		if strings.HasPrefix(line, "#") {
+			flush()
+			title, lines = strings.TrimSpace(strings.TrimLeft(line, "#")), nil
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return sections
+}
+
+// keyedSections returns the keys of the sections, their heading followed
+// by their position among those sharing it, with their texts.
+func keyedSections(text string) (keys []string, texts map[string]string) {
+	texts = make(map[string]string)
+	count := make(map[string]int)
+	for _, s := range splitSections(text) {
+		count[s.title]++
+		k := fmt.Sprintf("%s\x00%d", s.title, count[s.title])
+		keys = append(keys, k)
+		texts[k] = s.text
+	}
+	return keys, texts
+}
+
+// diffSections returns the sections of a template changed from text a to
+// b, in the order of b followed by those only in a.
+func diffSections(template, a, b string) []SectionChange {
+	oldKeys, oldTexts := keyedSections(a)
+	keys, newTexts := keyedSections(b)
+	for _, k := range oldKeys {
+		if _, ok := newTexts[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	changes := make([]SectionChange, 0)
+	for _, k := range keys {
+		oldText, newText := oldTexts[k], newTexts[k]
+		if oldText == newText {
+			continue
+		}
+		title, _, _ := strings.Cut(k, "\x00")
+		changes = append(changes, SectionChange{
+			Template: template,
+			Title:    title,
+			Old:      oldText,
+			New:      newText,
+			Lines:    diffLines(oldText, newText),
+		})
+	}
+	return changes
+}
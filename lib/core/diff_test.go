@@ -0,0 +1,94 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+const diffConfig = `{"name": "T", "templates": [{"name": "t", "filenames": ["t.tmpl"]}], "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+	"a": {"type": "checkbox", "tag": "a", "title": "A"},
+	"c": {"type": "checkbox", "tag": "c", "title": "C", "condition": "tags.a == true"},
+	"s": {"type": "string", "tag": "s", "title": "S"}}}]}`
+
+const diffTemplate = `Intro
+# A
+{{if .Tags.a}}A chosen{{else}}A not chosen{{end}}
+# S
+Value {{.Tags.s}}
+# End
+End.
+`
+
+func newDiffProject(t *testing.T) *Project {
+	return newTestProject(t, diffConfig, map[string]string{"t.tmpl": diffTemplate})
+}
+
+func TestDiff(t *testing.T) {
+	p := newDiffProject(t)
+	d, err := p.Diff(
+		ProjectExport{Values: map[string]any{"a": true, "s": "x"}},
+		ProjectExport{Values: map[string]any{"a": false, "s": "y"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := []TagChange{{Tag: "a", Old: true, New: false}, {Tag: "s", Old: "x", New: "y"}}
+	if !reflect.DeepEqual(d.Inputs, inputs) {
+		t.Errorf("got inputs %v, want %v", d.Inputs, inputs)
+	}
+	// unchecked checkboxes are not among the tags
+	derived := []TagChange{{Tag: "c", Old: true, New: nil}}
+	if !reflect.DeepEqual(d.Derived, derived) {
+		t.Errorf("got derived %v, want %v", d.Derived, derived)
+	}
+	titles := make([]string, 0)
+	for _, s := range d.Sections {
+		titles = append(titles, s.Title)
+	}
+	if !reflect.DeepEqual(titles, []string{"A", "S"}) {
+		t.Errorf("got the sections %v, want A and S", titles)
+	}
+	if d.Empty() {
+		t.Errorf("the diff is empty")
+	}
+}
+
+func TestDiffKeepsTheProject(t *testing.T) {
+	p := newDiffProject(t)
+	mustSet(t, p, "s", "mine")
+	audit, changes := p.AuditLog(), p.Changes()
+
+	same := ProjectExport{Values: map[string]any{"a": true}}
+	d, err := p.Diff(same, same)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		t.Errorf("got %+v for the same answers", d)
+	}
+	if p.GetFeature("s").GetValue() != "mine" || p.Tags["a"] == true {
+		t.Errorf("the answers are not restored")
+	}
+	if !reflect.DeepEqual(p.AuditLog(), audit) || len(p.Changes()) != len(changes) {
+		t.Errorf("the diff is recorded in the audit log or the undo stack")
+	}
+}
+
+func TestDiffSections(t *testing.T) {
+	a := "Intro\n# Same\nx\n# Same\ny\n# Gone\nz\n"
+	b := "Intro\n# Same\nx\n# Same\nchanged\n# New\nw\n"
+	changes := diffSections("t", a, b)
+	type key struct{ title, old, new string }
+	got := make([]key, 0)
+	for _, c := range changes {
+		got = append(got, key{c.Title, c.Old, c.New})
+	}
+	want := []key{
+		{"Same", "# Same\ny", "# Same\nchanged"},
+		{"New", "", "# New\nw"},
+		{"Gone", "# Gone\nz", ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
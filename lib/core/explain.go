@@ -0,0 +1,128 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Explanation tells where the value and the disabled state of a tag come
+// from. A value without a Condition was answered, or is the default.
+type Explanation struct {
+	Tag      string `json:"tag"`
+	Title    string `json:"title,omitempty"`
+	Value    any    `json:"value"`
+	Disabled bool   `json:"disabled"`
+	// the expression that computed the value
+	Condition *ExpressionTrace `json:"condition,omitempty"`
+	// the expression that disabled the feature, or one of its parents, or
+	// that keeps it enabled
+	DisabledOn *ExpressionTrace `json:"disabled_on,omitempty"`
+}
+
+// ExpressionTrace is an expression as last evaluated, with the
+// explanations of the tags it read.
+type ExpressionTrace struct {
+	// tag of the feature declaring the expression
+	Feature    string         `json:"feature"`
+	Expression string         `json:"expression"`
+	Result     any            `json:"result"`
+	Inputs     []*Explanation `json:"inputs"`
+}
+
+// expressionTrace is recorded by the evaluation of the dependency graph.
+type expressionTrace struct {
+	feature    Feature
+	expression string
+	reads      []string
+	result     any
+}
+
+// trace records the expression of a node after its evaluation.
+func (p *Project) trace(n *evaluationNode) {
+	f, ok := n.feature.(Feature)
+	if !ok || n.expression == "" || f.GetTag() == "" {
+		return
+	}
+	t := &expressionTrace{feature: f, expression: n.expression, reads: n.reads}
+	if p.traces == nil {
+		p.traces = make(map[string]*expressionTrace)
+	}
+	if n.kind == EXPRESSION_DISABLED_ON {
+		t.result = f.IsDisabled()
+	} else {
+		t.result = featureTagValue(f)
+	}
+	p.traces[n.kind+":"+f.GetTag()] = t
+}
+
+// Explain returns the expressions that set the value and the disabled state
+// of the tag at the last Validate, down to the answers they read.
+func (p *Project) Explain(tag string) (*Explanation, error) {
+	if p.GetFeature(tag) == nil {
+		return nil, fmt.Errorf("feature %s not found", tag)
+	}
+	return p.explain(tag, nil), nil
+}
+
+// explain builds the explanation of a tag; path holds the tags being
+// explained, whose inputs are not repeated within cycles.
+func (p *Project) explain(tag string, path []string) *Explanation {
+	e := &Explanation{Tag: tag, Value: p.Tags[tag]}
+	parents := p.featurePath(tag)
+	if len(parents) == 0 {
+		// a tag of the enclosing scope
+		return e
+	}
+	f := parents[len(parents)-1]
+	e.Title = f.GetTitle()
+	e.Value = featureTagValue(f)
+	e.Disabled = slices.ContainsFunc(parents, Feature.IsDisabled)
+	if slices.Contains(path, tag) {
+		return e
+	}
+	path = append(path, tag)
+
+	if t, ok := p.traces[EXPRESSION_CONDITION+":"+tag]; ok {
+		e.Condition = p.explainTrace(t, path)
+	}
+	// the outermost disabled feature explains the state of its children
+	for _, parent := range parents {
+		if t, ok := p.traces[EXPRESSION_DISABLED_ON+":"+parent.GetTag()]; ok && (parent.IsDisabled() || parent == f) {
+			e.DisabledOn = p.explainTrace(t, path)
+			break
+		}
+	}
+	return e
+}
+
+func (p *Project) explainTrace(t *expressionTrace, path []string) *ExpressionTrace {
+	trace := &ExpressionTrace{
+		Feature:    t.feature.GetTag(),
+		Expression: t.expression,
+		Result:     t.result,
+		Inputs:     make([]*Explanation, 0, len(t.reads)),
+	}
+	for _, read := range t.reads {
+		trace.Inputs = append(trace.Inputs, p.explain(read, path))
+	}
+	return trace
+}
+
+// featurePath returns the features from the outermost one to the one with
+// the tag, nil when there is none.
+func (p *Project) featurePath(tag string) []Feature {
+	var find func(features []Feature, path []Feature) []Feature
+	find = func(features []Feature, path []Feature) []Feature {
+		for _, f := range features {
+			next := append(slices.Clone(path), f)
+			if f.GetTag() == tag {
+				return next
+			}
+			if found := find(f.GetChildren(), next); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(p.Features, nil)
+}
@@ -0,0 +1,363 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gterranova/go-bexpr"
+	"github.com/gterranova/go-bexpr/grammar"
+	"github.com/mitchellh/pointerstructure"
+)
+
+const (
+	EXPRESSION_CONDITION   = "condition"
+	EXPRESSION_DISABLED_ON = "disabled_on"
+)
+
+// expressionHelpers maps the helpers available in expressions to their
+// number of arguments.
+var expressionHelpers = map[string]int{
+	"date":         1,
+	"today":        0,
+	"days_between": 2,
+	"add_days":     2,
+	"count":        1,
+	"any":          1,
+	"all":          1,
+	"sum":          1,
+}
+
+// Evaluator wraps the bexpr evaluator, which panics on math with missing or
+// mismatched operands. Math with a missing tag evaluates to false, as bexpr
+// does for comparisons with missing tags; the other panics are errors.
+type Evaluator struct {
+	*bexpr.Evaluator
+	// selectors of the math operands
+	operands [][]string
+}
+
+func (eval *Evaluator) Evaluate(datum any) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if eval.missingOperand(datum) {
+				result, err = false, nil
+			} else {
+				result, err = false, fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	return eval.Evaluator.Evaluate(datum)
+}
+
+// missingOperand tells whether a math operand has no value in the datum.
+func (eval *Evaluator) missingOperand(datum any) bool {
+	for _, path := range eval.operands {
+		ptr := pointerstructure.Pointer{Parts: path, Config: pointerstructure.Config{TagName: "bexpr"}}
+		if value, err := ptr.Get(datum); err != nil || value == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// NewEvaluator expands the expression helpers and creates the evaluator.
+func NewEvaluator(expression string) (*Evaluator, error) {
+	expanded, err := ExpandExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	eval, err := bexpr.CreateEvaluator(expanded)
+	if err != nil {
+		return nil, err
+	}
+	ast, err := grammar.Parse("", []byte(expanded))
+	if err != nil {
+		return nil, err
+	}
+	return &Evaluator{Evaluator: eval, operands: mathOperands(ast)}, nil
+}
+
+// ExpandExpression rewrites the helpers into plain bexpr, which only knows
+// a single math operation on each side of a comparison:
+//
+//	date("2024-01-31")    the day number of the date
+//	today()               the current day number
+//	days_between(a, b)    b - a
+//	add_days(a, n)        a + n
+//	count(tags.a)         number of items of the array a
+//	any(tags.a.x)         true if any item of a sets x
+//	all(tags.a.x)         true if all the items of a set x
+//	sum(tags.a.x)         sum of x over the items of a
+//
+// Helpers whose arguments are all literals are replaced by their result.
+// Otherwise days_between and add_days cannot take the result of another
+// math helper, as bexpr has no parentheses to group it.
+func ExpandExpression(expression string) (string, error) {
+	var out strings.Builder
+
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		if c == '"' || c == '`' {
+			end := skipString(expression, i)
+			out.WriteString(expression[i:end])
+			i = end
+			continue
+		}
+		if !isIdentChar(c) || (i > 0 && (isIdentChar(expression[i-1]) || expression[i-1] == '.')) {
+			out.WriteByte(c)
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(expression) && isIdentChar(expression[end]) {
+			end++
+		}
+		name := expression[i:end]
+		open := end
+		for open < len(expression) && expression[open] == ' ' {
+			open++
+		}
+		arity, ok := expressionHelpers[name]
+		if !ok || open == len(expression) || expression[open] != '(' {
+			out.WriteString(name)
+			i = end
+			continue
+		}
+
+		args, close, err := splitArguments(expression, open)
+		if err != nil {
+			return "", err
+		}
+		if len(args) != arity {
+			return "", fmt.Errorf("%s expects %d arguments, got %d", name, arity, len(args))
+		}
+		for k, arg := range args {
+			if args[k], err = ExpandExpression(arg); err != nil {
+				return "", err
+			}
+		}
+		replacement, err := expandHelper(name, args)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(replacement)
+		i = close
+	}
+	return out.String(), nil
+}
+
+func expandHelper(name string, args []string) (string, error) {
+	switch name {
+	case "date":
+		s, err := strconv.Unquote(args[0])
+		if err != nil {
+			return "", fmt.Errorf("date expects a quoted date, got %s", args[0])
+		}
+		day, err := ParseDay(s)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(day, 10), nil
+	case "today":
+		return "today", nil
+	case "days_between":
+		a, aerr := strconv.ParseInt(args[0], 10, 64)
+		b, berr := strconv.ParseInt(args[1], 10, 64)
+		if aerr == nil && berr == nil {
+			return strconv.FormatInt(b-a, 10), nil
+		}
+		if err := checkMathOperands(name, args); err != nil {
+			return "", err
+		}
+		return args[1] + " - " + args[0], nil
+	case "add_days":
+		a, aerr := strconv.ParseInt(args[0], 10, 64)
+		n, nerr := strconv.ParseInt(args[1], 10, 64)
+		if aerr == nil && nerr == nil {
+			return strconv.FormatInt(a+n, 10), nil
+		}
+		if err := checkMathOperands(name, args); err != nil {
+			return "", err
+		}
+		return args[0] + " + " + args[1], nil
+	case "count":
+		if path := strings.Split(args[0], "."); len(path) != 2 || path[0] != "tags" {
+			return "", fmt.Errorf("count expects an array as tags.<array>, got %s", args[0])
+		}
+		return args[0] + "." + ARRAY_COUNT, nil
+	case "any", "all", "sum":
+		path := strings.Split(args[0], ".")
+		if len(path) != 3 || path[0] != "tags" {
+			return "", fmt.Errorf("%s expects an item tag as tags.<array>.<tag>, got %s", name, args[0])
+		}
+		return strings.Join([]string{path[0], path[1], name, path[2]}, "."), nil
+	}
+	return "", fmt.Errorf("unknown helper %s", name)
+}
+
+// checkMathOperands returns an error if an expanded argument of the helper
+// is math itself, such as the result of a nested add_days.
+func checkMathOperands(name string, args []string) error {
+	for _, arg := range args {
+		if _, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			continue
+		}
+		if strings.ContainsAny(arg, " \t+*/%") {
+			return fmt.Errorf("%s cannot take %s as an argument: bexpr allows a single math operation on each side of a comparison", name, arg)
+		}
+	}
+	return nil
+}
+
+// splitArguments returns the comma separated arguments of the call whose
+// opening parenthesis is at open, and the position after the closing one.
+func splitArguments(expression string, open int) ([]string, int, error) {
+	args := make([]string, 0)
+	depth := 0
+	start := open + 1
+	for i := open; i < len(expression); {
+		switch expression[i] {
+		case '"', '`':
+			i = skipString(expression, i)
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				if arg := strings.TrimSpace(expression[start:i]); arg != "" || len(args) > 0 {
+					args = append(args, arg)
+				}
+				return args, i + 1, nil
+			}
+		case ',':
+			if depth == 1 {
+				args = append(args, strings.TrimSpace(expression[start:i]))
+				start = i + 1
+			}
+		}
+		i++
+	}
+	return nil, 0, fmt.Errorf("missing ) in %q", expression[open:])
+}
+
+// skipString returns the position after the string literal starting at i.
+func skipString(expression string, i int) int {
+	quote := expression[i]
+	for j := i + 1; j < len(expression); j++ {
+		switch expression[j] {
+		case '\\':
+			if quote == '"' {
+				j++
+			}
+		case quote:
+			return j + 1
+		}
+	}
+	return len(expression)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// ParseExpression expands the helpers and parses the expression without
+// creating an evaluator.
+func ParseExpression(expression string) (grammar.Expression, error) {
+	expanded, err := ExpandExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	ast, err := grammar.Parse("", []byte(expanded))
+	if err != nil {
+		return nil, err
+	}
+	return ast.(grammar.Expression), nil
+}
+
+// ExpressionTags returns the tags referenced as `tags.<name>` by the
+// expression, in order of first appearance.
+func ExpressionTags(expression string) ([]string, error) {
+	ast, err := ParseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0)
+	for _, sel := range expressionSelectors(ast) {
+		if len(sel.Path) > 1 && sel.Path[0] == "tags" && IndexOf(tags, sel.Path[1]) == -1 {
+			tags = append(tags, sel.Path[1])
+		}
+	}
+	return tags, nil
+}
+
+func expressionSelectors(node any) []grammar.Selector {
+	selectors := make([]grammar.Selector, 0)
+	for _, v := range expressionValues(node) {
+		if v.Type == grammar.ValueTypeReflect {
+			selectors = append(selectors, v.Selector)
+		}
+	}
+	return selectors
+}
+
+// mathOperands returns the paths of the selectors taking part in math.
+func mathOperands(node any) [][]string {
+	operands := make([][]string, 0)
+	switch t := node.(type) {
+	case *grammar.UnaryExpression:
+		operands = append(operands, mathOperands(t.Operand)...)
+	case *grammar.BinaryExpression:
+		operands = append(operands, mathOperands(t.Left)...)
+		operands = append(operands, mathOperands(t.Right)...)
+	case *grammar.MatchExpression:
+		if t.Left != nil {
+			operands = append(operands, mathOperands(t.Left)...)
+		}
+		if t.Right != nil {
+			operands = append(operands, mathOperands(t.Right)...)
+		}
+	case *grammar.ExpressionValue:
+		if t.Right == nil {
+			operands = append(operands, mathOperands(t.Left)...)
+			break
+		}
+		for _, v := range expressionValues(t) {
+			if v.Type == grammar.ValueTypeReflect {
+				operands = append(operands, v.Selector.Path)
+			}
+		}
+	}
+	return operands
+}
+
+// expressionValues returns the selectors and the literals of the expression.
+func expressionValues(node any) []*grammar.MatchValue {
+	values := make([]*grammar.MatchValue, 0)
+	switch t := node.(type) {
+	case *grammar.UnaryExpression:
+		values = append(values, expressionValues(t.Operand)...)
+	case *grammar.BinaryExpression:
+		values = append(values, expressionValues(t.Left)...)
+		values = append(values, expressionValues(t.Right)...)
+	case *grammar.MatchExpression:
+		if t.Left != nil {
+			values = append(values, expressionValues(t.Left)...)
+		}
+		if t.Right != nil {
+			values = append(values, expressionValues(t.Right)...)
+		}
+	case *grammar.ExpressionValue:
+		if t.Left != nil {
+			values = append(values, expressionValues(t.Left)...)
+		}
+		if t.Right != nil {
+			values = append(values, expressionValues(t.Right)...)
+		}
+	case *grammar.MatchValue:
+		values = append(values, t)
+	}
+	return values
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestEvaluatorMath(t *testing.T) {
+	datum := map[string]any{"tags": map[string]any{"n": int64(3), "b": true, "null": nil}}
+	tests := []struct {
+		expression string
+		want       any
+		fails      bool
+	}{
+		{expression: "tags.n * 2 > 5", want: true},
+		{expression: "tags.n * 2 > 6", want: false},
+		// math with missing tags is false, like comparisons
+		{expression: "tags.missing > 3", want: false},
+		{expression: "tags.missing * 2 > 3", want: false},
+		{expression: "tags.n + tags.missing > 3", want: false},
+		{expression: "tags.null * 2 > 3", want: false},
+		// broken math is an error
+		{expression: "tags.b * 2 > 3", fails: true},
+		{expression: "tags.n / 0 > 1", fails: true},
+	}
+	for _, tt := range tests {
+		eval, err := NewEvaluator(tt.expression)
+		if err != nil {
+			t.Fatalf("NewEvaluator(%q): %v", tt.expression, err)
+		}
+		got, err := eval.Evaluate(datum)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", tt.expression, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q = %v, %v; want %v", tt.expression, got, err, tt.want)
+		}
+	}
+}
+
+func TestExpandNestedHelpers(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+		fails      bool
+	}{
+		{expression: `days_between(tags.a, tags.b) > 60`, want: `tags.b - tags.a > 60`},
+		{expression: `add_days(date("2024-01-30"), 2) == add_days(tags.d, -3)`, want: `19754 == tags.d + -3`},
+		// literals are folded at any depth
+		{expression: `add_days(add_days(date("2024-01-30"), 1), 1) > tags.d`, want: `19754 > tags.d`},
+		// math cannot be grouped in bexpr
+		{expression: `add_days(add_days(tags.d, 1), 2) > today()`, fails: true},
+		{expression: `days_between(tags.a, add_days(tags.b, 3)) > 60`, fails: true},
+	}
+	for _, tt := range tests {
+		got, err := ExpandExpression(tt.expression)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %q", tt.expression, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q = %q, %v; want %q", tt.expression, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// The functions available to the report templates, besides the text/template
+// builtins:
+//
+//	hasTag "tag"               whether the tag is set to a true value
+//	tagValue "tag"             the value of the tag, nil when unset
+//	anyTag "tag1" "tag2" ...   whether any of the tags is set
+//	allTags "tag1" "tag2" ...  whether all of the tags are set
+//	feature "tag"              the feature with the tag
+//	title "tag"                the title of the feature with the tag
+//	infoUrl "tag"              the info url of the feature with the tag
+//	formatNumber v [digits]    v with the separators of the project language
+//	formatCurrency v [code]    v as an amount in the currency, EUR by default
+//	formatDate v [layout]      a date, a day number or an ISO 8601 string,
+//	                           in the layout or the one of the language, or
+//	                           an empty string when v is unset
+//	plural n "one" "many"      one when n is 1, many otherwise
+//	escape s                   s with the markdown characters escaped
+//	tableRow cell ...          a pipe table row of the cells
+//	tableRule align ...        the separator line of a pipe table, each
+//	                           align is left, right, center or empty
+//
+// The language is the one of the project at the time the template runs.
+
+type locale struct {
+	decimal string
+	group   string
+	date    string
+	// whether the currency symbol precedes the amount
+	symbolFirst bool
+}
+
+var locales = map[string]locale{
+	"en": {".", ",", "2 January 2006", true},
+	"it": {",", ".", "02/01/2006", false},
+	"de": {",", ".", "02.01.2006", false},
+	"fr": {",", " ", "02/01/2006", false},
+	"es": {",", ".", "02/01/2006", false},
+}
+
+var currencySymbols = map[string]string{
+	"EUR": "€",
+	"USD": "$",
+	"GBP": "£",
+}
+
+func (p *Project) locale() locale {
+	if l, ok := locales[p.Language()]; ok {
+		return l
+	}
+	return locales["en"]
+}
+
+// templateFuncs returns the functions of the report templates, bound to the
+// project.
+func (p *Project) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"hasTag": func(tag string) bool {
+			return isTrue(p.Tags[tag])
+		},
+		"tagValue": func(tag string) any {
+			return p.Tags[tag]
+		},
+		"anyTag": func(tags ...string) bool {
+			for _, tag := range tags {
+				if isTrue(p.Tags[tag]) {
+					return true
+				}
+			}
+			return false
+		},
+		"allTags": func(tags ...string) bool {
+			for _, tag := range tags {
+				if !isTrue(p.Tags[tag]) {
+					return false
+				}
+			}
+			return len(tags) > 0
+		},
+		"feature": p.templateFeature,
+		"title": func(tag string) (string, error) {
+			f, err := p.templateFeature(tag)
+			if err != nil {
+				return "", err
+			}
+			return f.GetTitle(), nil
+		},
+		"infoUrl": func(tag string) (string, error) {
+			f, err := p.templateFeature(tag)
+			if err != nil {
+				return "", err
+			}
+			if fi, ok := f.(FeatureWithInfoUrl); ok {
+				return fi.GetInfoUrl(), nil
+			}
+			return "", nil
+		},
+		"formatNumber": func(value any, digits ...int) (string, error) {
+			d, err := ParseDecimal(value)
+			if err != nil {
+				return "", err
+			}
+			places := int32(0)
+			if len(digits) > 0 {
+				places = int32(digits[0])
+			} else if e := d.Exponent(); e < 0 {
+				places = -e
+			}
+			return formatDecimal(d, places, p.locale()), nil
+		},
+		"formatCurrency": func(value any, code ...string) (string, error) {
+			d, err := ParseDecimal(value)
+			if err != nil {
+				return "", err
+			}
+			currency := "EUR"
+			if len(code) > 0 {
+				currency = strings.ToUpper(code[0])
+			}
+			symbol, ok := currencySymbols[currency]
+			if !ok {
+				symbol = currency
+			}
+			l := p.locale()
+			amount := formatDecimal(d, 2, l)
+			if l.symbolFirst {
+				if strings.HasPrefix(amount, "-") {
+					return "-" + symbol + amount[1:], nil
+				}
+				return symbol + amount, nil
+			}
+			return amount + " " + symbol, nil
+		},
+		"formatDate": func(value any, layout ...string) (string, error) {
+			if value == nil || value == "" {
+				return "", nil
+			}
+			t, err := templateDate(value)
+			if err != nil {
+				return "", err
+			}
+			if len(layout) > 0 {
+				return t.Format(layout[0]), nil
+			}
+			return t.Format(p.locale().date), nil
+		},
+		"plural": func(n any, one, many string) (string, error) {
+			d, err := ParseDecimal(n)
+			if err != nil {
+				return "", err
+			}
+			if d.Equal(decimal.NewFromInt(1)) {
+				return one, nil
+			}
+			return many, nil
+		},
+		"escape": escapeMarkdown,
+		"tableRow": func(cells ...any) string {
+			var b strings.Builder
+			b.WriteString("|")
+			for _, cell := range cells {
+				text := strings.Join(strings.Fields(fmt.Sprint(cell)), " ")
+				b.WriteString(" " + strings.ReplaceAll(text, "|", "\\|") + " |")
+			}
+			return b.String()
+		},
+		"tableRule": func(aligns ...string) string {
+			var b strings.Builder
+			b.WriteString("|")
+			for _, align := range aligns {
+				switch align {
+				case "left", "l":
+					b.WriteString(":--|")
+				case "right", "r":
+					b.WriteString("--:|")
+				case "center", "c":
+					b.WriteString(":-:|")
+				default:
+					b.WriteString("---|")
+				}
+			}
+			return b.String()
+		},
+	}
+}
+
+func (p *Project) templateFeature(tag string) (Feature, error) {
+	if f := p.GetFeature(tag); f != nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("feature %s not found", tag)
+}
+
+// isTrue reports whether a tag value is set, as the if action would.
+func isTrue(value any) bool {
+	if n, ok := value.(json.Number); ok {
+		d, err := decimal.NewFromString(string(n))
+		return err == nil && !d.IsZero()
+	}
+	truth, _ := template.IsTrue(value)
+	return truth
+}
+
+// formatDecimal rounds d to places digits and groups the thousands.
+func formatDecimal(d decimal.Decimal, places int32, l locale) string {
+	s := d.StringFixed(places)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(l.group)
+		}
+		b.WriteRune(c)
+	}
+	if fraction != "" {
+		return sign + b.String() + l.decimal + fraction
+	}
+	return sign + b.String()
+}
+
+// templateDate converts the forms a date takes in the project: the day
+// numbers of the tags, the strings of the features and times.
+func templateDate(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case int64:
+		return time.Unix(v*86400, 0).UTC(), nil
+	case int:
+		return time.Unix(int64(v)*86400, 0).UTC(), nil
+	case string:
+		day, err := ParseDay(v)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(day*86400, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("cannot convert %v (%T) to date", value, value)
+}
+
+// escapeMarkdown escapes the characters with a meaning in the markdown of
+// the templates, so that answers show verbatim.
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_{}[]<>|#", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,320 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependencyCycleError is returned when mutually dependent expressions do
+// not settle on a stable set of tags.
+type DependencyCycleError struct {
+	Tags []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return fmt.Sprintf("cyclic dependency between tags: %s", strings.Join(e.Tags, " -> "))
+}
+
+type evaluationNode struct {
+	feature    FeatureWithExpressions
+	kind       string
+	expression string
+	reads      []string
+	produces   []string
+	// errors of nested features do not stop the evaluation, as Checklist
+	// and Checkform always did when validating their children, but are
+	// reported among the validation errors
+	nested bool
+}
+
+func (n *evaluationNode) evaluate(p *Project) (changed bool, err error) {
+	if n.kind == EXPRESSION_DISABLED_ON {
+		changed, err = n.feature.EvaluateDisabledOn(p)
+	} else {
+		changed, err = n.feature.EvaluateCondition(p)
+	}
+	if err != nil && n.nested {
+		tag := ""
+		if f, ok := n.feature.(Feature); ok {
+			tag = f.GetTag()
+		}
+		p.errors = append(p.errors, &ValidationError{Tag: tag, Rule: n.kind, Message: err.Error()})
+		return changed, nil
+	}
+	return
+}
+
+type evaluationEdge struct {
+	to  *evaluationNode
+	tag string
+}
+
+// DependencyGraph links every condition and disabled_on expression of a
+// project to the expressions producing the tags it reads. Expressions are
+// grouped in strongly connected components, sorted in topological order.
+type DependencyGraph struct {
+	nodes      []*evaluationNode
+	edges      map[*evaluationNode][]evaluationEdge
+	components [][]*evaluationNode
+}
+
+func NewDependencyGraph(features []Feature) (*DependencyGraph, error) {
+	g := &DependencyGraph{
+		nodes: make([]*evaluationNode, 0),
+		edges: make(map[*evaluationNode][]evaluationEdge),
+	}
+
+	for _, f := range features {
+		if err := g.addFeature(f, false); err != nil {
+			return nil, err
+		}
+	}
+
+	producers := make(map[string][]*evaluationNode)
+	for _, n := range g.nodes {
+		for _, t := range n.produces {
+			producers[t] = append(producers[t], n)
+		}
+	}
+	var prev *evaluationNode
+	for _, n := range g.nodes {
+		for _, t := range n.reads {
+			for _, from := range producers[t] {
+				g.edges[from] = append(g.edges[from], evaluationEdge{to: n, tag: t})
+			}
+		}
+		// a feature disabled state is settled before its value is computed
+		if prev != nil && prev.feature == n.feature {
+			g.edges[prev] = append(g.edges[prev], evaluationEdge{to: n, tag: n.produces[0]})
+		}
+		prev = n
+	}
+
+	g.sort()
+	return g, nil
+}
+
+func (g *DependencyGraph) addFeature(f Feature, nested bool) error {
+	if fe, ok := f.(FeatureWithExpressions); ok {
+		if expr := fe.GetDisabledOn(); expr != "" {
+			if err := g.addNode(fe, EXPRESSION_DISABLED_ON, expr, featureTags(f), nested); err != nil {
+				return err
+			}
+		}
+		if expr := fe.GetCondition(); expr != "" {
+			if err := g.addNode(fe, EXPRESSION_CONDITION, expr, []string{f.GetTag()}, nested); err != nil {
+				return err
+			}
+		}
+		// the nested scope is evaluated at once, after the tags it reads
+		if fs, ok := f.(FeatureWithScope); ok {
+			reads, err := fs.ScopeTags()
+			if err != nil {
+				return err
+			}
+			g.nodes = append(g.nodes, &evaluationNode{
+				feature:  fe,
+				kind:     EXPRESSION_CONDITION,
+				reads:    reads,
+				produces: []string{f.GetTag()},
+				nested:   nested,
+			})
+		}
+	}
+	for _, child := range f.GetChildren() {
+		if err := g.addFeature(child, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *DependencyGraph) addNode(f FeatureWithExpressions, kind, expression string, produces []string, nested bool) error {
+	reads, err := ExpressionTags(expression)
+	if err != nil {
+		return fmt.Errorf("failed to parse expression %q: %v", expression, err)
+	}
+	g.nodes = append(g.nodes, &evaluationNode{
+		feature:    f,
+		kind:       kind,
+		expression: expression,
+		reads:      reads,
+		produces:   produces,
+		nested:     nested,
+	})
+	return nil
+}
+
+// featureTags returns the tag of the feature and of all its descendants.
+func featureTags(f Feature) []string {
+	tags := make([]string, 0)
+	if f.GetTag() != "" {
+		tags = append(tags, f.GetTag())
+	}
+	for _, child := range f.GetChildren() {
+		tags = append(tags, featureTags(child)...)
+	}
+	return tags
+}
+
+// sort computes the strongly connected components with Tarjan's algorithm.
+func (g *DependencyGraph) sort() {
+	index := make(map[*evaluationNode]int)
+	lowlink := make(map[*evaluationNode]int)
+	onStack := make(map[*evaluationNode]bool)
+	stack := make([]*evaluationNode, 0)
+	components := make([][]*evaluationNode, 0)
+
+	var connect func(n *evaluationNode)
+	connect = func(n *evaluationNode) {
+		index[n] = len(index)
+		lowlink[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+
+		for _, e := range g.edges[n] {
+			if _, visited := index[e.to]; !visited {
+				connect(e.to)
+				lowlink[n] = min(lowlink[n], lowlink[e.to])
+			} else if onStack[e.to] {
+				lowlink[n] = min(lowlink[n], index[e.to])
+			}
+		}
+
+		if lowlink[n] == index[n] {
+			component := make([]*evaluationNode, 0)
+			for {
+				m := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[m] = false
+				component = append([]*evaluationNode{m}, component...)
+				if m == n {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, n := range g.nodes {
+		if _, visited := index[n]; !visited {
+			connect(n)
+		}
+	}
+
+	// Tarjan emits components in reverse topological order
+	g.components = make([][]*evaluationNode, len(components))
+	for i, c := range components {
+		g.components[len(components)-1-i] = c
+	}
+}
+
+func (g *DependencyGraph) isCyclic(component []*evaluationNode) bool {
+	if len(component) > 1 {
+		return true
+	}
+	for _, e := range g.edges[component[0]] {
+		if e.to == component[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// cycle returns the tags along one dependency cycle of the component,
+// starting and ending with the same tag.
+func (g *DependencyGraph) cycle(component []*evaluationNode) []string {
+	type step struct {
+		node *evaluationNode
+		prev *step
+		tag  string
+	}
+
+	start := component[0]
+	members := make(map[*evaluationNode]bool)
+	for _, n := range component {
+		members[n] = true
+	}
+
+	visited := make(map[*evaluationNode]bool)
+	queue := []*step{{node: start}}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, e := range g.edges[s.node] {
+			if !members[e.to] {
+				continue
+			}
+			next := &step{node: e.to, prev: s, tag: e.tag}
+			if e.to == start {
+				tags := make([]string, 0)
+				for t := next; t.prev != nil; t = t.prev {
+					tags = append([]string{t.tag}, tags...)
+				}
+				return append(tags, tags[0])
+			}
+			if !visited[e.to] {
+				visited[e.to] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// Cycles returns one tag cycle for every group of mutually dependent
+// expressions. Cycles are allowed as long as they settle during evaluation.
+func (g *DependencyGraph) Cycles() [][]string {
+	cycles := make([][]string, 0)
+	for _, component := range g.components {
+		if g.isCyclic(component) {
+			cycles = append(cycles, g.cycle(component))
+		}
+	}
+	return cycles
+}
+
+// Evaluate runs every expression once its inputs are settled. Mutually
+// dependent expressions are repeated until they stop changing; if they keep
+// oscillating a DependencyCycleError reports the tags involved.
+func (g *DependencyGraph) Evaluate(p *Project) (changed bool, err error) {
+	for _, component := range g.components {
+		if !g.isCyclic(component) {
+			var nchanged bool
+			if nchanged, err = component[0].evaluate(p); err != nil {
+				return
+			}
+			p.trace(component[0])
+			if nchanged {
+				p.UpdateTags()
+				changed = true
+			}
+			continue
+		}
+
+		settled := false
+		for count := 0; count <= 2*len(component); count++ {
+			cchanged := false
+			for _, n := range component {
+				var nchanged bool
+				if nchanged, err = n.evaluate(p); err != nil {
+					return
+				}
+				p.trace(n)
+				if nchanged {
+					p.UpdateTags()
+					cchanged = true
+				}
+			}
+			if !cchanged {
+				settled = true
+				break
+			}
+			changed = true
+		}
+		if !settled {
+			return changed, &DependencyCycleError{Tags: g.cycle(component)}
+		}
+	}
+	return
+}
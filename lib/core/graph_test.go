@@ -0,0 +1,85 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvaluationFollowsDependencies(t *testing.T) {
+	// declared after the tags they read
+	p := newTestProject(t, `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+		"c": {"type": "checkbox", "tag": "c", "title": "C", "condition": "tags.b == true"},
+		"b": {"type": "checkbox", "tag": "b", "title": "B", "condition": "tags.a == true"},
+		"a": {"type": "checkbox", "tag": "a", "title": "A"}}},
+		{"type": "checkform", "tag": "more", "title": "More", "disabled_on": "tags.c == true", "properties": {
+		"d": {"type": "checkbox", "tag": "d", "title": "D"}}}]}`, nil)
+
+	mustSet(t, p, "a", true)
+	if p.Tags["b"] != true || p.Tags["c"] != true {
+		t.Errorf("got b %v and c %v, want both set by a", p.Tags["b"], p.Tags["c"])
+	}
+	if f := p.Features[1]; !f.IsDisabled() {
+		t.Errorf("more is not disabled by c")
+	}
+	if _, ok := p.Tags["d"]; ok {
+		t.Errorf("the tags of a disabled feature are set")
+	}
+
+	g, err := p.DependencyGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cycles := g.Cycles(); len(cycles) != 0 {
+		t.Errorf("unexpected cycles %v", cycles)
+	}
+}
+
+func TestSettlingCycle(t *testing.T) {
+	p := newTestProject(t, `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+		"a": {"type": "checkbox", "tag": "a", "title": "A"},
+		"x": {"type": "checkbox", "tag": "x", "title": "X", "condition": "tags.a == true or tags.y == true"},
+		"y": {"type": "checkbox", "tag": "y", "title": "Y", "condition": "tags.x == true"}}}]}`, nil)
+
+	g, err := p.DependencyGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cycles := g.Cycles()
+	if len(cycles) != 1 || len(cycles[0]) != 3 || cycles[0][0] != cycles[0][2] {
+		t.Fatalf("got cycles %v, want x and y", cycles)
+	}
+
+	mustSet(t, p, "a", true)
+	if p.Tags["x"] != true || p.Tags["y"] != true {
+		t.Errorf("got x %v and y %v, want both set", p.Tags["x"], p.Tags["y"])
+	}
+}
+
+func TestOscillatingCycle(t *testing.T) {
+	l := memoryLoader{"config.json": []byte(`{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+		"x": {"type": "checkbox", "tag": "x", "title": "X", "condition": "tags.x != true"}}}]}`)}
+	p := NewProject(l)
+	_, err := p.Validate("")
+	var cycle *DependencyCycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("got %v, want a DependencyCycleError", err)
+	}
+	if len(cycle.Tags) != 2 || cycle.Tags[0] != "x" {
+		t.Errorf("got cycle %v, want x -> x", cycle.Tags)
+	}
+}
+
+func TestNestedExpressionErrorsAreReported(t *testing.T) {
+	p := newTestProject(t, `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+		"b": {"type": "checkbox", "tag": "b", "title": "B"},
+		"c": {"type": "checkbox", "tag": "c", "title": "C", "condition": "tags.b * 2 > 1"}}}]}`, nil)
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("unexpected errors with b missing: %v", errs)
+	}
+
+	mustSet(t, p, "b", true)
+	errs := p.Errors()
+	if len(errs) != 1 || errs[0].Tag != "c" || errs[0].Rule != EXPRESSION_CONDITION {
+		t.Fatalf("expected the error of the condition of c, got %v", errs)
+	}
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// memoryLoader keeps a package in memory.
+type memoryLoader map[string][]byte
+
+func (l memoryLoader) Name() string {
+	return "test"
+}
+
+func (l memoryLoader) Get(filename string) ([]byte, bool) {
+	data, ok := l[filename]
+	return data, ok
+}
+
+func (l memoryLoader) Set(filename string, data []byte) error {
+	l[filename] = data
+	return nil
+}
+
+func (l memoryLoader) SaveAs(filename string) error {
+	return nil
+}
+
+func (l memoryLoader) List(dir string) []string {
+	files := make([]string, 0)
+	for filename := range l {
+		if strings.HasPrefix(filename, dir+"/") {
+			files = append(files, filename)
+		}
+	}
+	slices.Sort(files)
+	return files
+}
+
+// newTestProject loads a package made of config.json and the other files.
+func newTestProject(t *testing.T, config string, files map[string]string) *Project {
+	t.Helper()
+	l := memoryLoader{"config.json": []byte(config)}
+	for filename, data := range files {
+		l[filename] = []byte(data)
+	}
+	var p *Project
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("cannot load the package: %v", r)
+			}
+		}()
+		p = NewProject(l)
+	}()
+	if _, err := p.Validate(""); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	return p
+}
+
+func mustSet(t *testing.T, p *Project, tag string, value any) {
+	t.Helper()
+	if err := p.SetFeature(tag, value); err != nil {
+		t.Fatalf("SetFeature(%s, %v): %v", tag, value, err)
+	}
+}
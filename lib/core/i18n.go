@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// translatableKeys are the feature texts taken from the translation files.
+// Everything else, tags and expressions first, is language independent.
+var translatableKeys = []string{"title", "section", "info_url", "info_urls", "item_title", "message", "messages"}
+
+// translations maps a tag to its translated texts.
+type translations map[string]map[string]any
+
+// Languages returns the languages of the package: the default one, if
+// declared, then the languages of the feature definitions.
+func (p *Project) Languages() []string {
+	langs := make([]string, 0)
+	if p.DefaultLang != "" {
+		langs = append(langs, p.DefaultLang)
+	}
+	for _, def := range p.FeatureDefs {
+		if def.Lang != "" && !slices.Contains(langs, def.Lang) {
+			langs = append(langs, def.Lang)
+		}
+	}
+	return langs
+}
+
+// Language returns the language the features are shown in.
+func (p *Project) Language() string {
+	if p.Lang == "" {
+		return p.DefaultLang
+	}
+	return p.Lang
+}
+
+// SetLanguage reloads the features with the texts of the given language,
+// keeping the answers. An empty lang restores the texts of config.json.
+func (p *Project) SetLanguage(lang string) error {
+	if lang == p.Lang {
+		return nil
+	}
+	if lang != "" && !slices.Contains(p.Languages(), lang) {
+		return fmt.Errorf("language %q not available", lang)
+	}
+
+	export := p.ExportData()
+	dirty := p.Dirty()
+	p.Lang = lang
+	if err := p.LoadFeatures(); err != nil {
+		return err
+	}
+	for _, f := range p.Features {
+		if err := f.ApplyDefaults(); err != nil {
+			return err
+		}
+	}
+	if err := p.LoadProjectData(export); err != nil {
+		return err
+	}
+	p.SetDirty(dirty)
+	return nil
+}
+
+// MatchLanguage returns the package language best matching an
+// Accept-Language header, or an empty string if none does.
+func (p *Project) MatchLanguage(accept string) string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	accepted := make([]weighted, 0)
+	for _, part := range strings.Split(accept, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		lang, _, _ = strings.Cut(strings.ToLower(lang), "-")
+		if lang != "" && q > 0 {
+			accepted = append(accepted, weighted{lang, q})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].q > accepted[j].q
+	})
+
+	langs := p.Languages()
+	for _, a := range accepted {
+		if slices.Contains(langs, a.lang) {
+			return a.lang
+		}
+	}
+	return ""
+}
+
+// Templates returns the templates for the current language, that is those
+// declaring it and those declaring no language at all.
+func (p *Project) Templates() []*TemplateDef {
+	lang := p.Language()
+	templates := make([]*TemplateDef, 0)
+	for _, t := range p.TemplateDefs {
+		if t.Lang == "" || lang == "" || t.Lang == lang {
+			templates = append(templates, t)
+		}
+	}
+	return templates
+}
+
+// loadTranslations reads the translation files of a language. They follow
+// the layout of the feature definitions, so that a full features.<lang>.json
+// works as well as one holding just tags and texts.
+func loadTranslations(loader ResourceLoader, defs []*FeatureDef, lang string) (translations, error) {
+	texts := make(translations)
+	for _, def := range defs {
+		if def.Lang != lang {
+			continue
+		}
+		for _, filename := range def.Filenames {
+			content, ok := loader.Get(filename)
+			if !ok {
+				return nil, fmt.Errorf("file %s not found", filename)
+			}
+			var data any
+			if err := json.Unmarshal(content, &data); err != nil {
+				return nil, fmt.Errorf("cannot read %s: %v", filename, err)
+			}
+			texts.collect(data, "", "")
+		}
+	}
+	return texts, nil
+}
+
+// textTag returns the tag the texts of a definition belong to: its own tag
+// or, in the features and properties maps, its key.
+func textTag(def map[string]any, key, parent string) string {
+	if tag, ok := def["tag"].(string); ok && tag != "" {
+		return tag
+	}
+	if parent == "features" || parent == "properties" {
+		return key
+	}
+	return ""
+}
+
+func (texts translations) collect(data any, key, parent string) {
+	switch t := data.(type) {
+	case map[string]any:
+		if tag := textTag(t, key, parent); tag != "" {
+			for _, k := range translatableKeys {
+				if v, ok := t[k]; ok {
+					if texts[tag] == nil {
+						texts[tag] = make(map[string]any)
+					}
+					texts[tag][k] = v
+				}
+			}
+		}
+		for k, v := range t {
+			texts.collect(v, k, key)
+		}
+	case []any:
+		for _, v := range t {
+			texts.collect(v, "", key)
+		}
+	}
+}
+
+// translate returns a copy of the definition with the translated texts.
+func (texts translations) translate(data any, key, parent string) any {
+	switch t := data.(type) {
+	case map[string]any:
+		def := make(map[string]any, len(t))
+		for k, v := range t {
+			def[k] = texts.translate(v, k, key)
+		}
+		if tag := textTag(t, key, parent); tag != "" {
+			for k, v := range texts[tag] {
+				def[k] = v
+			}
+		}
+		return def
+	case []any:
+		list := make([]any, len(t))
+		for i, v := range t {
+			list[i] = texts.translate(v, "", key)
+		}
+		return list
+	}
+	return data
+}
+
+// translateJSON translates a feature definition.
+func (texts translations) translateJSON(data []byte) ([]byte, error) {
+	var def any
+	// keep the numbers as written, decimals in particular
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&def); err != nil {
+		return nil, err
+	}
+	return json.Marshal(texts.translate(def, "", ""))
+}
@@ -0,0 +1,308 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/gterranova/go-bexpr/grammar"
+)
+
+const (
+	LINT_ERROR   = "error"
+	LINT_WARNING = "warning"
+
+	LINT_INVALID_EXPRESSION = "invalid_expression"
+	LINT_UNKNOWN_TAG        = "unknown_tag"
+	LINT_UNUSED_TAG         = "unused_tag"
+	LINT_DUPLICATE_TAG      = "duplicate_tag"
+	LINT_TYPE_MISMATCH      = "type_mismatch"
+)
+
+var templateTagRegexp = regexp.MustCompile(`\.Tags\.([A-Za-z0-9_]+)|index\s+\.Tags\s+"([^"]+)"`)
+
+type LintIssue struct {
+	Severity   string `json:"severity"`
+	Kind       string `json:"kind"`
+	Feature    string `json:"feature,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Expression string `json:"expression,omitempty"`
+	Message    string `json:"message"`
+}
+
+func (issue LintIssue) String() string {
+	if issue.Feature != "" {
+		return fmt.Sprintf("%s: %s: %s", issue.Severity, issue.Feature, issue.Message)
+	}
+	return fmt.Sprintf("%s: %s", issue.Severity, issue.Message)
+}
+
+type linter struct {
+	project  *Project
+	types    map[string]string
+	counts   map[string]int
+	used     map[string]bool
+	issues   []LintIssue
+	features []Feature
+}
+
+// Lint statically checks the expressions and tags of the project features
+// and returns the issues found, errors first.
+func (p *Project) Lint() []LintIssue {
+	l := &linter{
+		project:  p,
+		types:    make(map[string]string),
+		counts:   make(map[string]int),
+		used:     make(map[string]bool),
+		issues:   make([]LintIssue, 0),
+		features: make([]Feature, 0),
+	}
+	for _, f := range p.Features {
+		l.collect(f)
+	}
+	for tag, count := range l.counts {
+		if count > 1 {
+			l.report(LINT_ERROR, LINT_DUPLICATE_TAG, "", tag, "", fmt.Sprintf("tag %q is declared %d times", tag, count))
+		}
+	}
+	for _, f := range l.features {
+		l.lintFeature(f)
+	}
+	l.collectTemplateTags()
+	for _, f := range l.features {
+		if _, ok := l.types[f.GetTag()]; ok && !l.used[f.GetTag()] {
+			l.report(LINT_WARNING, LINT_UNUSED_TAG, f.GetTag(), f.GetTag(), "", fmt.Sprintf("tag %q is not used by any expression or template", f.GetTag()))
+		}
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		return l.issues[i].Severity == LINT_ERROR && l.issues[j].Severity != LINT_ERROR
+	})
+	return l.issues
+}
+
+func (l *linter) lintFeature(f Feature) {
+	if fe, ok := f.(FeatureWithExpressions); ok {
+		want := TagType(f)
+		if want == "" || want == "array" || want == "list" {
+			want = "bool"
+		}
+		l.lintExpression(f, fe.GetCondition(), want)
+		l.lintExpression(f, fe.GetDisabledOn(), "bool")
+	}
+	if fr, ok := f.(FeatureWithRules); ok {
+		rules := fr.GetRules()
+		l.lintExpression(f, rules.ValidIf, "bool")
+		if rules.Pattern != "" {
+			if _, err := regexp.Compile(rules.Pattern); err != nil {
+				l.report(LINT_ERROR, LINT_INVALID_EXPRESSION, f.GetTag(), "", rules.Pattern, fmt.Sprintf("invalid pattern: %v", err))
+			}
+		}
+	}
+	if a, ok := f.(*Array); ok {
+		l.lintArray(a)
+	}
+}
+
+// lintArray checks the expressions of the array items, where the item tags
+// are visible next to the project ones.
+func (l *linter) lintArray(a *Array) {
+	schema, err := a.Schema()
+	if err != nil {
+		l.report(LINT_ERROR, LINT_INVALID_EXPRESSION, a.GetTag(), "", "", err.Error())
+		return
+	}
+
+	outer := l.types
+	l.types = make(map[string]string)
+	for k, v := range outer {
+		l.types[k] = v
+	}
+	items := make([]Feature, 0)
+	var collect func(f Feature)
+	collect = func(f Feature) {
+		items = append(items, f)
+		if t := TagType(f); t != "" && f.GetTag() != "" {
+			l.types[f.GetTag()] = t
+		}
+		for _, child := range f.GetChildren() {
+			collect(child)
+		}
+	}
+	collect(schema)
+	for _, f := range items {
+		l.lintFeature(f)
+	}
+	l.types = outer
+}
+
+func (l *linter) collect(f Feature) {
+	l.features = append(l.features, f)
+	if tag := f.GetTag(); tag != "" {
+		l.counts[tag]++
+		if t := TagType(f); t != "" {
+			l.types[tag] = t
+		}
+	}
+	for _, child := range f.GetChildren() {
+		l.collect(child)
+	}
+}
+
+func (l *linter) collectTemplateTags() {
+	for _, t := range l.project.TemplateDefs {
+		for _, tmplFile := range t.Filenames {
+			content, ok := l.project.Loader.Get(tmplFile)
+			if !ok {
+				continue
+			}
+			for _, m := range templateTagRegexp.FindAllStringSubmatch(string(content), -1) {
+				l.used[m[1]+m[2]] = true
+			}
+		}
+	}
+}
+
+func (l *linter) report(severity, kind, feature, tag, expression, message string) {
+	l.issues = append(l.issues, LintIssue{
+		Severity:   severity,
+		Kind:       kind,
+		Feature:    feature,
+		Tag:        tag,
+		Expression: expression,
+		Message:    message,
+	})
+}
+
+func (l *linter) lintExpression(f Feature, expression string, want string) {
+	if expression == "" {
+		return
+	}
+	ast, err := ParseExpression(expression)
+	if err != nil {
+		l.report(LINT_ERROR, LINT_INVALID_EXPRESSION, f.GetTag(), "", expression, fmt.Sprintf("cannot parse %q: %v", expression, err))
+		return
+	}
+	for _, sel := range expressionSelectors(ast) {
+		if len(sel.Path) < 2 || sel.Path[0] != "tags" {
+			continue
+		}
+		tag := sel.Path[1]
+		l.used[tag] = true
+		if _, ok := l.types[tag]; ok {
+			continue
+		}
+		if l.counts[tag] > 0 {
+			l.report(LINT_ERROR, LINT_UNKNOWN_TAG, f.GetTag(), tag, expression, fmt.Sprintf("tag %q in %q is a group and never has a value", tag, expression))
+		} else {
+			l.report(LINT_ERROR, LINT_UNKNOWN_TAG, f.GetTag(), tag, expression, fmt.Sprintf("unknown tag %q in %q", tag, expression))
+		}
+	}
+	l.lintTypes(f, expression, ast, want)
+}
+
+// lintTypes walks the expression tree checking that the operands of every
+// comparison agree; want is the type expected by the parent node.
+func (l *linter) lintTypes(f Feature, expression string, node any, want string) {
+	mismatch := func(message string) {
+		l.report(LINT_ERROR, LINT_TYPE_MISMATCH, f.GetTag(), "", expression, fmt.Sprintf("%s in %q", message, expression))
+	}
+
+	switch t := node.(type) {
+	case *grammar.UnaryExpression:
+		l.lintTypes(f, expression, t.Operand, "bool")
+	case *grammar.BinaryExpression:
+		l.lintTypes(f, expression, t.Left, "bool")
+		l.lintTypes(f, expression, t.Right, "bool")
+	case *grammar.ExpressionValue:
+		if got, name := l.valueType(t); got != "" && want != "" && got != want {
+			mismatch(fmt.Sprintf("%s is %s, expected %s", name, got, want))
+		}
+	case *grammar.MatchExpression:
+		left, lname := l.valueType(t.Left)
+		right, rname := l.valueType(t.Right)
+		switch t.Operator {
+		case grammar.MatchIn, grammar.MatchNotIn, grammar.MatchIsEmpty, grammar.MatchIsNotEmpty:
+		case grammar.MatchMatches, grammar.MatchNotMatches:
+			if left != "" && left != "string" {
+				mismatch(fmt.Sprintf("%s is %s and cannot be matched", lname, left))
+			}
+		default:
+			if left != "" && right != "" && left != right {
+				mismatch(fmt.Sprintf("comparing %s %s with %s %s", left, lname, right, rname))
+			} else if left == "bool" || right == "bool" {
+				switch t.Operator {
+				case grammar.MatchLower, grammar.MatchLowerOrEqual, grammar.MatchHigher, grammar.MatchHigherOrEqual:
+					mismatch("ordering boolean values")
+				}
+			}
+		}
+	}
+}
+
+func (l *linter) valueType(ev *grammar.ExpressionValue) (string, string) {
+	if ev == nil {
+		return "", ""
+	}
+	if ev.Operator != grammar.MathOpValue {
+		left, name := l.operandType(ev.Left)
+		right, _ := l.operandType(ev.Right)
+		if left == "" {
+			return right, name
+		}
+		return left, name
+	}
+	return l.operandType(ev.Left)
+}
+
+func (l *linter) operandType(operand any) (string, string) {
+	switch t := operand.(type) {
+	case *grammar.ExpressionValue:
+		return l.valueType(t)
+	case *grammar.MatchValue:
+		switch t.Type {
+		case grammar.ValueTypeBool:
+			return "bool", t.Raw
+		case grammar.ValueTypeInt, grammar.ValueTypeUint, grammar.ValueTypeFloat32, grammar.ValueTypeFloat64:
+			return "number", t.Raw
+		case grammar.ValueTypeString:
+			return "string", fmt.Sprintf("%q", t.Raw)
+		case grammar.ValueTypeReflect:
+			path := t.Selector.Path
+			if len(path) > 2 && path[0] == "tags" && l.types[path[1]] == "array" {
+				switch path[2] {
+				case ARRAY_COUNT, ARRAY_SUM:
+					return "number", t.Selector.String()
+				case ARRAY_ANY, ARRAY_ALL:
+					return "bool", t.Selector.String()
+				}
+				return "", ""
+			}
+			if len(path) > 1 && path[0] == "tags" {
+				return l.types[path[1]], t.Selector.String()
+			}
+		}
+	}
+	return "", ""
+}
+
+// TagType returns the type of the value a feature stores under its tag, or
+// an empty string for groups that never appear in Project.Tags.
+func TagType(f Feature) string {
+	switch t := f.(type) {
+	case *Checkbox, *Option:
+		return "bool"
+	case *Number, *Decimal, *Date:
+		// dates are compared and added to as day numbers
+		return "number"
+	case *String:
+		return "string"
+	case *Array:
+		return "array"
+	case *Select:
+		if t.Multiple {
+			return "list"
+		}
+	}
+	return ""
+}
@@ -23,6 +23,34 @@ type FeatureWithInfoUrl interface {
 	GetInfoUrl() string
 }
 
+// FeatureWithExpressions is implemented by features whose value or disabled
+// state is computed from a `condition` or `disabled_on` expression.
+type FeatureWithExpressions interface {
+	GetCondition() string
+	GetDisabledOn() string
+	EvaluateCondition(i any) (changed bool, err error)
+	EvaluateDisabledOn(i any) (changed bool, err error)
+}
+
+// FeatureWithTagValue is implemented by features exposing their value to
+// expressions in a different form than the one used by the forms.
+type FeatureWithTagValue interface {
+	GetTagValue() any
+}
+
+// FeatureWithScope is implemented by features evaluating the expressions
+// of their children in a scope of their own; ScopeTags returns the tags of
+// the enclosing scope read by those expressions.
+type FeatureWithScope interface {
+	ScopeTags() ([]string, error)
+}
+
+// FeatureWithRules is implemented by features declaring validation rules.
+type FeatureWithRules interface {
+	GetRules() *Rules
+	CheckRules(i any) ValidationErrors
+}
+
 var knownTypes = map[string]reflect.Type{
 	"checkform": reflect.TypeOf(Checkform{}),
 	"checklist": reflect.TypeOf(Checklist{}),
@@ -31,23 +59,51 @@ var knownTypes = map[string]reflect.Type{
 	"option":    reflect.TypeOf(Option{}),
 	"string":    reflect.TypeOf(String{}),
 	"number":    reflect.TypeOf(Number{}),
+	"date":      reflect.TypeOf(Date{}),
+	"decimal":   reflect.TypeOf(Decimal{}),
+	"array":     reflect.TypeOf(Array{}),
 }
 
 var _ Feature = (*Checklist)(nil)
+var _ FeatureWithExpressions = (*Checklist)(nil)
 var _ Feature = (*Checkform)(nil)
+var _ FeatureWithExpressions = (*Checkform)(nil)
 var _ Feature = (*Checkbox)(nil)
 var _ FeatureWithInfoUrl = (*Checkbox)(nil)
+var _ FeatureWithExpressions = (*Checkbox)(nil)
 
 var _ Feature = (*Select)(nil)
 var _ FeatureWithInfoUrl = (*Select)(nil)
+var _ FeatureWithExpressions = (*Select)(nil)
+var _ FeatureWithRules = (*Select)(nil)
 
 var _ Feature = (*Option)(nil)
+var _ FeatureWithExpressions = (*Option)(nil)
 
 var _ Feature = (*String)(nil)
 var _ FeatureWithInfoUrl = (*String)(nil)
+var _ FeatureWithExpressions = (*String)(nil)
+var _ FeatureWithRules = (*String)(nil)
 
 var _ Feature = (*Number)(nil)
 var _ FeatureWithInfoUrl = (*Number)(nil)
+var _ FeatureWithExpressions = (*Number)(nil)
+var _ FeatureWithRules = (*Number)(nil)
+
+var _ Feature = (*Date)(nil)
+var _ FeatureWithInfoUrl = (*Date)(nil)
+var _ FeatureWithExpressions = (*Date)(nil)
+var _ FeatureWithTagValue = (*Date)(nil)
+
+var _ Feature = (*Decimal)(nil)
+var _ FeatureWithInfoUrl = (*Decimal)(nil)
+var _ FeatureWithExpressions = (*Decimal)(nil)
+
+var _ Feature = (*Array)(nil)
+var _ FeatureWithInfoUrl = (*Array)(nil)
+var _ FeatureWithExpressions = (*Array)(nil)
+var _ FeatureWithTagValue = (*Array)(nil)
+var _ FeatureWithScope = (*Array)(nil)
 
 type OptionLabelValue struct {
 	Label    string `json:"label"`
@@ -1,55 +1,66 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 
 	"github.com/gterranova/go-bexpr"
 )
 
+// Number is an integer answer. A number without a default is empty until
+// it is answered, so that the required rule can tell it from zero.
 type Number struct {
 	Type       string `json:"type"`
 	Title      string `json:"title"`
 	Value      int64  `json:"value" bexpr:"value"`
 	Disabled   bool   `json:"disabled,omitempty"`
-	Default    int64  `json:"default,omitempty"`
+	Default    *int64 `json:"default,omitempty"`
 	Tag        string `json:"tag,omitempty"`
 	Condition  string `json:"condition,omitempty"`
 	DisabledOn string `json:"disabled_on,omitempty"`
 	InfoUrl    string `json:"info_url,omitempty"`
+	Rules      `bexpr:"-"`
 
-	valueEvaluator    *bexpr.Evaluator `json:"-" bexpr:"-"`
-	disabledEvaluator *bexpr.Evaluator `json:"-" bexpr:"-"`
+	// no value was set, Value is zero
+	empty bool `json:"-" bexpr:"-"`
+
+	valueEvaluator    *Evaluator `json:"-" bexpr:"-"`
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
 }
 
 func (feature *Number) Validate(tag string, i any) (changed bool, err error) {
-	if len(feature.DisabledOn) > 0 {
-		result, eval_err := feature.disabledEvaluator.Evaluate(i)
-		if eval_err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, eval_err)
-		}
-		b, _ := bexpr.CoerceBool(result)
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Disabled != b
-		feature.Disabled = b
+	var valueChanged bool
+
+	if changed, err = feature.EvaluateDisabledOn(i); err != nil {
+		return
 	}
-	if changed {
-		eval, err := bexpr.CreateEvaluator(feature.Condition)
+	valueChanged, err = feature.EvaluateCondition(i)
+	return changed || valueChanged, err
+}
+
+func (feature *Number) EvaluateCondition(i any) (changed bool, err error) {
+	if !feature.Disabled && feature.Tag != "" && feature.valueEvaluator != nil {
+		result, err := feature.valueEvaluator.Evaluate(i)
 		if err != nil {
-			return changed, fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
-		} else {
-			feature.valueEvaluator = eval
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, err)
 		}
+		b, _ := bexpr.CoerceInt64(result)
+		changed = feature.empty || feature.Value != b
+		feature.Value, feature.empty = b, false
 	}
-	if !feature.Disabled && feature.Tag != "" && feature.valueEvaluator != nil {
-		result, eval_err := feature.valueEvaluator.Evaluate(i)
-		if eval_err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, eval_err)
+	return
+}
+
+func (feature *Number) EvaluateDisabledOn(i any) (changed bool, err error) {
+	if len(feature.DisabledOn) > 0 {
+		result, err := feature.disabledEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
 		}
-		b, _ := bexpr.CoerceInt64(result)
-		//fmt.Printf("Result of expression %q evaluation: %t\n", feature.Condition, result)
-		changed = changed || feature.Value != b
-		feature.Value = b
+		b, _ := bexpr.CoerceBool(result)
+		changed = feature.Disabled != b
+		feature.Disabled = b
 	}
 	return
 }
@@ -57,7 +68,7 @@ func (feature *Number) Validate(tag string, i any) (changed bool, err error) {
 func (feature *Number) ApplyDefaults() error {
 	// apply defaults
 	if feature.Condition != "" {
-		eval, err := bexpr.CreateEvaluator(feature.Condition)
+		eval, err := NewEvaluator(feature.Condition)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
 		} else {
@@ -66,7 +77,7 @@ func (feature *Number) ApplyDefaults() error {
 	}
 
 	if feature.DisabledOn != "" {
-		eval, err := bexpr.CreateEvaluator(feature.DisabledOn)
+		eval, err := NewEvaluator(feature.DisabledOn)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
 		} else {
@@ -74,7 +85,14 @@ func (feature *Number) ApplyDefaults() error {
 		}
 	}
 
-	return feature.SetValue(feature.Default)
+	if err := feature.Rules.compile(); err != nil {
+		return err
+	}
+
+	if feature.Default == nil {
+		return feature.SetValue(nil)
+	}
+	return feature.SetValue(*feature.Default)
 }
 
 func (feature *Number) Set(tag string, value any) error {
@@ -97,21 +115,38 @@ func (feature *Number) GetChildren() []Feature {
 }
 
 func (feature *Number) GetValue() any {
+	if feature.empty {
+		return nil
+	}
 	return feature.Value
 }
 func (feature *Number) SetValue(value any) error {
 	switch v := value.(type) {
+	case nil:
+		feature.Value, feature.empty = 0, true
 	case int64:
-		feature.Value = v
+		feature.Value, feature.empty = v, false
 	case int:
 		if i64, err := strconv.ParseInt(strconv.Itoa(v), 10, 64); err == nil {
-			feature.Value = i64
+			feature.Value, feature.empty = i64, false
 		} else {
 			fmt.Println(i64, "is not an integer.")
 		}
 	case float64:
 		if i64, err := strconv.ParseInt(fmt.Sprintf("%.0f", v), 10, 64); err == nil {
-			feature.Value = i64
+			feature.Value, feature.empty = i64, false
+		}
+	case json.Number:
+		if i64, err := v.Int64(); err == nil {
+			feature.Value, feature.empty = i64, false
+		} else if f, err := v.Float64(); err == nil {
+			return feature.SetValue(f)
+		}
+	case string:
+		if v == "" {
+			feature.Value, feature.empty = 0, true
+		} else {
+			fmt.Printf("cannot convert number to int64 %v %T", value, value)
 		}
 	default:
 		fmt.Printf("cannot convert number to int64 %v %T", value, value)
@@ -140,6 +175,23 @@ func (feature *Number) GetInfoUrl() string {
 	return feature.InfoUrl
 }
 
+func (feature *Number) GetCondition() string {
+	return feature.Condition
+}
+
+func (feature *Number) GetDisabledOn() string {
+	return feature.DisabledOn
+}
+
+func (feature *Number) GetRules() *Rules {
+	return &feature.Rules
+}
+
+// CheckRules implements FeatureWithRules.
+func (feature *Number) CheckRules(i any) ValidationErrors {
+	return feature.Rules.check(feature.Tag, feature.GetValue(), i)
+}
+
 func (p *Number) MarshalJSON() ([]byte, error) {
 	marshaller := &NumberFormly{p}
 	return marshaller.MarshalJSON()
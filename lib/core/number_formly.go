@@ -10,11 +10,13 @@ type NumberFormly struct {
 
 func (feature *NumberFormly) MarshalJSON() ([]byte, error) {
 	type jsonFeature struct {
-		Type     string `json:"type"`
-		Title    string `json:"title"`
-		Value    int64  `json:"value,omitempty"`
-		Disabled bool   `json:"disabled,omitempty"`
-		Default  int64  `json:"default,omitempty"`
+		Type     string   `json:"type"`
+		Title    string   `json:"title"`
+		Value    *int64   `json:"value,omitempty"`
+		Disabled bool     `json:"disabled,omitempty"`
+		Default  *int64   `json:"default,omitempty"`
+		Minimum  *float64 `json:"minimum,omitempty"`
+		Maximum  *float64 `json:"maximum,omitempty"`
 		Widget   struct {
 			FormlyConfig map[string]any `json:"formlyConfig,omitempty"`
 		} `json:"widget,omitempty"`
@@ -22,17 +24,21 @@ func (feature *NumberFormly) MarshalJSON() ([]byte, error) {
 	foo := jsonFeature{
 		Type:     feature.Type,
 		Title:    feature.Title,
-		Value:    feature.Value,
 		Disabled: feature.Disabled,
 		Default:  feature.Default,
+		Minimum:  feature.Min,
+		Maximum:  feature.Max,
 	}
 
+	if !feature.empty {
+		foo.Value = &feature.Value
+	}
+
+	props := make(map[string]any)
 	if feature.InfoUrl != "" {
-		foo.Widget.FormlyConfig = make(map[string]any)
-		foo.Widget.FormlyConfig["props"] = map[string]any{
-			"info_url": feature.InfoUrl,
-		}
+		props["info_url"] = feature.InfoUrl
 	}
+	foo.Widget.FormlyConfig = feature.Rules.formlyConfig(props)
 
 	valueBytes, err := json.Marshal(foo)
 	if err != nil {
@@ -7,37 +7,49 @@ import (
 )
 
 type Option struct {
-	Type              string           `json:"type"`
-	Title             string           `json:"title"`
-	Value             bool             `json:"value" bexpr:"value"`
-	Disabled          bool             `json:"disabled,omitempty"`
-	Default           bool             `json:"default,omitempty"`
-	Tag               string           `json:"tag,omitempty"`
-	Condition         string           `json:"condition,omitempty"`
-	DisabledOn        string           `json:"disabled_on,omitempty"`
-	valueEvaluator    *bexpr.Evaluator `json:"-" bexpr:"-"`
-	disabledEvaluator *bexpr.Evaluator `json:"-" bexpr:"-"`
+	Type              string     `json:"type"`
+	Title             string     `json:"title"`
+	Value             bool       `json:"value" bexpr:"value"`
+	Disabled          bool       `json:"disabled,omitempty"`
+	Default           bool       `json:"default,omitempty"`
+	Tag               string     `json:"tag,omitempty"`
+	Condition         string     `json:"condition,omitempty"`
+	DisabledOn        string     `json:"disabled_on,omitempty"`
+	valueEvaluator    *Evaluator `json:"-" bexpr:"-"`
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
 }
 
 func (feature *Option) Validate(tag string, i any) (changed bool, err error) {
+	var disabledChanged bool
+
+	if changed, err = feature.EvaluateCondition(i); err != nil {
+		return
+	}
+	disabledChanged, err = feature.EvaluateDisabledOn(i)
+	return changed || disabledChanged, err
+}
+
+func (feature *Option) EvaluateCondition(i any) (changed bool, err error) {
 	if feature.Tag != "" && feature.valueEvaluator != nil {
-		result, eval_err := feature.valueEvaluator.Evaluate(i)
-		if eval_err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, eval_err)
+		result, err := feature.valueEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, err)
 		}
 		b, _ := bexpr.CoerceBool(result)
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Value != b
+		changed = feature.Value != b
 		feature.Value = b
 	}
+	return
+}
+
+func (feature *Option) EvaluateDisabledOn(i any) (changed bool, err error) {
 	if len(feature.DisabledOn) > 0 {
-		result, eval_err := feature.disabledEvaluator.Evaluate(i)
-		if eval_err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, eval_err)
+		result, err := feature.disabledEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
 		}
 		b, _ := bexpr.CoerceBool(result)
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Disabled != b
+		changed = feature.Disabled != b
 		feature.Disabled = b
 	}
 	return
@@ -46,7 +58,7 @@ func (feature *Option) Validate(tag string, i any) (changed bool, err error) {
 func (feature *Option) ApplyDefaults() error {
 	// apply defaults
 	if feature.Condition != "" {
-		eval, err := bexpr.CreateEvaluator(feature.Condition)
+		eval, err := NewEvaluator(feature.Condition)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
 		} else {
@@ -55,7 +67,7 @@ func (feature *Option) ApplyDefaults() error {
 	}
 
 	if feature.DisabledOn != "" {
-		eval, err := bexpr.CreateEvaluator(feature.DisabledOn)
+		eval, err := NewEvaluator(feature.DisabledOn)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
 		} else {
@@ -110,3 +122,11 @@ func (feature *Option) GetType() string {
 func (feature *Option) GetTitle() string {
 	return feature.Title
 }
+
+func (feature *Option) GetCondition() string {
+	return feature.Condition
+}
+
+func (feature *Option) GetDisabledOn() string {
+	return feature.DisabledOn
+}
@@ -6,9 +6,9 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
-	"time"
 
 	"terra9.it/checkmate/core/converter"
 	"terra9.it/checkmate/loader"
@@ -16,6 +16,8 @@ import (
 
 const (
 	CACHE_FEATURES = true
+	// table style of the reference docs of the packages
+	DOCX_TABLE_STYLE = "StileTable"
 )
 
 type ResourceLoader interface {
@@ -25,31 +27,79 @@ type ResourceLoader interface {
 	SaveAs(filename string) error
 }
 
+// ResourceLister is implemented by loaders able to enumerate the files of
+// a package directory.
+type ResourceLister interface {
+	List(dir string) []string
+}
+
+// FeatureDef declares the translation files of a language.
 type FeatureDef struct {
 	Lang      string   `json:"lang"`
 	Filenames []string `json:"filenames"`
 }
 
 type TemplateDef struct {
-	Name         string             `json:"name"`
-	Filenames    []string           `json:"filenames"`
-	Format       string             `json:"format"`
-	ReferenceDoc string             `json:"reference_doc"`
-	Template     *template.Template `json:"-"`
+	Name         string   `json:"name"`
+	Lang         string   `json:"lang,omitempty"`
+	Filenames    []string `json:"filenames"`
+	Format       string   `json:"format"`
+	ReferenceDoc string   `json:"reference_doc"`
+	// style of the tables of docx reports, DOCX_TABLE_STYLE when empty
+	TableStyle string `json:"table_style,omitempty"`
+	// page header, footer and logo of pdf reports, and the look of the
+	// custom styles of pdf and html reports, see converter.PdfOptions
+	Header string                        `json:"header,omitempty"`
+	Footer string                        `json:"footer,omitempty"`
+	Logo   string                        `json:"logo,omitempty"`
+	Styles map[string]converter.PdfStyle `json:"styles,omitempty"`
+	// css file of html reports, see converter.HtmlOptions
+	Stylesheet string             `json:"stylesheet,omitempty"`
+	Template   *template.Template `json:"-"`
+}
+
+// IsDocument reports whether the template renders to a document rather
+// than to text, see Project.Render.
+func (t *TemplateDef) IsDocument() bool {
+	return t.Format == "docx" || t.Format == "pdf" || t.Format == "html"
 }
 
 type Project struct {
 	Name    string `json:"name"`
 	Author  string `json:"author"`
 	License string `json:"license"`
+	// Strict projects refuse to render while validation rules fail
+	Strict bool `json:"strict,omitempty"`
+	// language of the texts in config.json, the other languages are
+	// declared by FeatureDefs
+	DefaultLang string        `json:"default_lang,omitempty"`
+	FeatureDefs []*FeatureDef `json:"features_defs,omitempty"`
+	// language the features are translated to, see SetLanguage
+	Lang string `json:"-"`
 
 	Features     []Feature      `json:"-" bexpr:"features"`
 	Tags         map[string]any `json:"tags" bexpr:"tags"`
+	Today        int64          `json:"-" bexpr:"today"`
 	TemplateDefs []*TemplateDef `json:"templates"`
 	Loader       ResourceLoader `json:"-"`
 
-	ProjectFile string `json:"-"`
-	isDirty     bool   `json:"-"`
+	// who changes the answers, recorded in the audit log
+	User string `json:"-"`
+
+	ProjectFile string       `json:"-"`
+	isDirty     bool         `json:"-"`
+	audit       []AuditEntry `json:"-"`
+	undo, redo  []Change     `json:"-"`
+	// the change being made by Batch
+	change *Change `json:"-"`
+	// set while loading answers, which are neither recorded nor undone
+	loading bool             `json:"-"`
+	graph   *DependencyGraph `json:"-"`
+	errors  ValidationErrors `json:"-"`
+	// expressions evaluated by the last Validate, see Explain
+	traces map[string]*expressionTrace `json:"-"`
+	// tags of the enclosing scope, visible next to the project tags
+	outer map[string]any `json:"-"`
 }
 
 type ProjectExport struct {
@@ -70,9 +120,20 @@ func NewProject(loader ResourceLoader) *Project {
 	}
 
 	for _, f := range p.Features {
-		f.ApplyDefaults()
+		if err := f.ApplyDefaults(); err != nil {
+			panic(err)
+		}
+	}
+	if data, ok := p.Loader.Get(AUDIT_FILE); ok {
+		if err := json.Unmarshal(data, &p.audit); err != nil {
+			panic(err)
+		}
 	}
-	if err := p.LoadProjectDataFromFile("data.json"); err != nil {
+	// the answers saved in the package are not changes
+	p.loading = true
+	err := p.LoadProjectDataFromFile("data.json")
+	p.loading = false
+	if err != nil {
 		if err.Error() != "file data.json not found" {
 			panic(err)
 		}
@@ -103,12 +164,18 @@ func (p *Project) UnmarshalJSON(bytes []byte) (err error) {
 
 	foo := _Project{}
 
+	type _ProjectSettings struct {
+		Name         string         `json:"name"`
+		Author       string         `json:"author"`
+		License      string         `json:"license"`
+		Strict       bool           `json:"strict,omitempty"`
+		DefaultLang  string         `json:"default_lang,omitempty"`
+		FeatureDefs  []*FeatureDef  `json:"features_defs,omitempty"`
+		TemplateDefs []*TemplateDef `json:"templates"`
+	}
 	type _ProjectJSON struct {
-		Name         string           `json:"name"`
-		Author       string           `json:"author"`
-		License      string           `json:"license"`
-		TemplateDefs []*TemplateDef   `json:"templates"`
-		Features     []map[string]any `json:"features"`
+		_ProjectSettings
+		Features []map[string]any `json:"features"`
 	}
 	pfi := _ProjectJSON{}
 	if CACHE_FEATURES {
@@ -124,6 +191,14 @@ func (p *Project) UnmarshalJSON(bytes []byte) (err error) {
 			isCached = true
 		}
 	}
+	if isCached {
+		// settings are read from config.json anyway, the cache may have
+		// been written by a version not knowing all of them
+		pfi._ProjectSettings = _ProjectSettings{}
+		if err := json.Unmarshal(bytes, &pfi._ProjectSettings); err != nil {
+			return err
+		}
+	}
 	if !isCached {
 		if err := json.Unmarshal(bytes, &pfi); err != nil {
 			return err
@@ -133,10 +208,24 @@ func (p *Project) UnmarshalJSON(bytes []byte) (err error) {
 	foo.Name = pfi.Name
 	foo.Author = pfi.Author
 	foo.License = pfi.License
+	foo.Strict = pfi.Strict
+	foo.DefaultLang = pfi.DefaultLang
+	foo.FeatureDefs = pfi.FeatureDefs
+	foo.Lang = p.Lang
 	foo.TemplateDefs = pfi.TemplateDefs
 	foo.Tags = make(map[string]any)
 	foo.ProjectFile = p.ProjectFile
 	foo.Loader = p.Loader
+	foo.User = p.User
+	foo.audit = p.audit
+	foo.undo, foo.redo = p.undo, p.redo
+
+	var texts translations
+	if foo.Lang != "" && foo.Lang != foo.DefaultLang {
+		if texts, err = loadTranslations(p.Loader, foo.FeatureDefs, foo.Lang); err != nil {
+			return err
+		}
+	}
 
 	foo.Features = make([]Feature, len(pfi.Features))
 	jsonFeatures := make([]map[string]any, len(pfi.Features))
@@ -150,6 +239,11 @@ func (p *Project) UnmarshalJSON(bytes []byte) (err error) {
 			if err != nil {
 				return err
 			}
+			if texts != nil {
+				if valueBytes, err = texts.translateJSON(valueBytes); err != nil {
+					return err
+				}
+			}
 
 			if err = json.Unmarshal(valueBytes, &value); err != nil {
 				return err
@@ -162,7 +256,13 @@ func (p *Project) UnmarshalJSON(bytes []byte) (err error) {
 			if !ok {
 				return fmt.Errorf("ref file %s not found", feature["$ref"].(string))
 			}
-			if err = json.Unmarshal(valueBytes, &value); err != nil {
+			refBytes := valueBytes
+			if texts != nil {
+				if refBytes, err = texts.translateJSON(valueBytes); err != nil {
+					return err
+				}
+			}
+			if err = json.Unmarshal(refBytes, &value); err != nil {
 				return err
 			}
 			value.SetTag(feature["tag"].(string))
@@ -183,11 +283,8 @@ func (p *Project) UnmarshalJSON(bytes []byte) (err error) {
 
 	if CACHE_FEATURES && !isCached {
 		onlyFeatures := _ProjectJSON{
-			Name:         foo.Name,
-			Author:       foo.Author,
-			License:      foo.License,
-			TemplateDefs: foo.TemplateDefs,
-			Features:     jsonFeatures,
+			_ProjectSettings: pfi._ProjectSettings,
+			Features:         jsonFeatures,
 		}
 		bYaml, _ := json.Marshal(onlyFeatures)
 		p.Loader.Set("cachedFeatures.json", bYaml)
@@ -234,29 +331,13 @@ func (p *Project) MarshalJSON() ([]byte, error) {
 }
 
 func (p *Project) LoadFeatures() error {
+	p.graph = nil
 	if content, ok := p.Loader.Get("config.json"); ok {
 		if err := json.Unmarshal(content, p); err != nil {
 			return err
 		}
 	}
 	/*
-		if p.FeatureDefs != nil {
-			for _, f := range p.FeatureDefs {
-				for _, tmplFile := range f.Filenames {
-					var i18nFeatures _Project
-					if content, ok := p.Loader.Get(tmplFile); ok {
-						if err := json.Unmarshal(content, &i18nFeatures); err != nil {
-							return err
-						}
-						for k, v := range i18nFeatures.Features {
-							p.Features[k+"."+f.Lang] = v
-						}
-					} else {
-						return fmt.Errorf("file %s not found", tmplFile)
-					}
-				}
-			}
-		}
 		if p.StatusDefs != nil {
 			tmpl := template.New("status")
 			for _, tmplFile := range p.StatusDefs.Filenames {
@@ -271,7 +352,7 @@ func (p *Project) LoadFeatures() error {
 	*/
 
 	for _, t := range p.TemplateDefs {
-		t.Template = template.New("template")
+		t.Template = template.New("template").Funcs(p.templateFuncs())
 		for _, tmplFile := range t.Filenames {
 			if content, ok := p.Loader.Get(tmplFile); ok {
 				t.Template = template.Must(t.Template.Parse(string(content)))
@@ -288,14 +369,38 @@ func (p *Project) UpdateTags() {
 		delete(p.Tags, k)
 	}
 
+	for k, v := range p.outer {
+		p.Tags[k] = v
+	}
+
+	p.Today = Today()
 	for _, f := range p.Features {
 		for _, t := range f.ApplicableFeatures() {
-			p.Tags[t.GetTag()] = t.GetValue()
+			// empty answers are missing to expressions, not nil
+			if v := featureTagValue(t); v != nil {
+				p.Tags[t.GetTag()] = v
+			}
 		}
 	}
 }
 
+// featureTagValue returns the value of the feature as seen by expressions.
+func featureTagValue(t Feature) any {
+	if tv, ok := t.(FeatureWithTagValue); ok {
+		return tv.GetTagValue()
+	}
+	return t.GetValue()
+}
+
+// SetFeature sets the answer of a tag, as a change that can be undone.
 func (p *Project) SetFeature(tag string, value any) error {
+	return p.Batch(func() error {
+		return p.setFeature(tag, value)
+	})
+}
+
+func (p *Project) setFeature(tag string, value any) error {
+	old := p.answer(tag)
 	for _, f := range p.Features {
 		if err := f.Set(tag, value); err != nil {
 			return err
@@ -304,104 +409,190 @@ func (p *Project) SetFeature(tag string, value any) error {
 	if _, err := p.Validate(tag); err != nil {
 		return err
 	}
+	p.record(tag, old, p.answer(tag))
+	p.changed(tag, old, p.answer(tag))
 	p.SetDirty(true)
 	return nil
 }
 
-func (p *Project) Validate(tag string) (changed bool, err error) {
-	var count int
-	for count = 0; count < 100; count++ {
-		changed = false
-		p.UpdateTags()
-		for _, f := range p.Features {
-			var fchanged bool
-			fchanged, err = f.Validate(tag, p)
-			if err != nil {
-				return
-			}
-			//if fchanged {
-			//	fmt.Println("tag:", tag, "against", f.GetTag(), "changed")
-			//}
+// AddArrayItem appends an empty item to the array with the tag, as a change
+// that can be undone.
+func (p *Project) AddArrayItem(tag string) error {
+	return p.changeArray(tag, func(a *Array) error {
+		_, err := a.AddItem()
+		return err
+	})
+}
 
-			changed = changed || fchanged
+// RemoveArrayItem removes the item at index from the array with the tag, as
+// a change that can be undone.
+func (p *Project) RemoveArrayItem(tag string, index int) error {
+	return p.changeArray(tag, func(a *Array) error {
+		return a.RemoveItem(index)
+	})
+}
+
+// MoveArrayItem moves the item of the array with the tag from a position to
+// another, as a change that can be undone.
+func (p *Project) MoveArrayItem(tag string, from, to int) error {
+	return p.changeArray(tag, func(a *Array) error {
+		return a.MoveItem(from, to)
+	})
+}
+
+// changeArray changes the items of an array like setFeature does with an
+// answer, recording the old and new items.
+func (p *Project) changeArray(tag string, change func(a *Array) error) error {
+	a, err := p.arrayFeature(tag)
+	if err != nil {
+		return err
+	}
+	return p.Batch(func() error {
+		old := p.answer(tag)
+		if err := change(a); err != nil {
+			return err
 		}
-		if err != nil {
+		if _, err := p.Validate(tag); err != nil {
+			return err
+		}
+		p.record(tag, old, p.answer(tag))
+		p.changed(tag, old, p.answer(tag))
+		p.SetDirty(true)
+		return nil
+	})
+}
+
+// arrayFeature returns the array with the tag, <array>.<index>.<tag> for an
+// array in an item of another one.
+func (p *Project) arrayFeature(tag string) (*Array, error) {
+	if a, ok := p.GetFeature(tag).(*Array); ok {
+		return a, nil
+	}
+	if head, rest, found := strings.Cut(tag, "."); found {
+		if outer, ok := p.GetFeature(head).(*Array); ok {
+			index, itemTag, _ := strings.Cut(rest, ".")
+			if n, err := strconv.Atoi(index); err == nil && n >= 0 && n < len(outer.items) {
+				return outer.items[n].project.arrayFeature(itemTag)
+			}
+		}
+	}
+	return nil, fmt.Errorf("array %s not found", tag)
+}
+
+// Validate evaluates every condition and disabled_on expression in
+// dependency order, then checks the validation rules of the enabled
+// features. The tag argument is no longer needed and kept for
+// compatibility with existing callers.
+func (p *Project) Validate(tag string) (changed bool, err error) {
+	var g *DependencyGraph
+	if g, err = p.DependencyGraph(); err != nil {
+		return
+	}
+	p.UpdateTags()
+	p.traces, p.errors = nil, nil
+	changed, err = g.Evaluate(p)
+	p.UpdateTags()
+	p.errors = append(p.errors, p.checkRules()...)
+	return
+}
+
+// Errors returns the validation rules failed at the last Validate.
+func (p *Project) Errors() ValidationErrors {
+	return p.errors
+}
+
+func (p *Project) checkRules() ValidationErrors {
+	errs := make(ValidationErrors, 0)
+	var check func(f Feature)
+	check = func(f Feature) {
+		if f.IsDisabled() {
 			return
 		}
-		if !changed {
-			p.UpdateTags()
-			for _, f := range p.Features {
-				var fchanged bool
-				fchanged, err = f.Validate("", p)
-				if err != nil {
-					return
+		switch t := f.(type) {
+		case FeatureWithRules:
+			errs = append(errs, t.CheckRules(p)...)
+		case *Array:
+			// item errors are reported with the item path
+			for n, item := range t.items {
+				for _, e := range item.project.errors {
+					errs = append(errs, &ValidationError{
+						Tag:     fmt.Sprintf("%s.%d.%s", t.Tag, n, e.Tag),
+						Rule:    e.Rule,
+						Message: e.Message,
+					})
 				}
-				//if fchanged {
-				//	fmt.Println("tag:", tag, "against", f.GetTag(), "changed")
-				//}
-				changed = changed || fchanged
-			}
-			if !changed {
-				break
 			}
 		}
-		count++
+		for _, child := range f.GetChildren() {
+			check(child)
+		}
 	}
-	if count == 100 {
-		err = fmt.Errorf("too many iterations in validation")
+	for _, f := range p.Features {
+		check(f)
 	}
-	return
+	return errs
 }
 
-func (p *Project) Render(t *TemplateDef) (output string, err error) {
+func (p *Project) DependencyGraph() (*DependencyGraph, error) {
+	if p.graph == nil {
+		g, err := NewDependencyGraph(p.Features)
+		if err != nil {
+			return nil, err
+		}
+		p.graph = g
+	}
+	return p.graph, nil
+}
 
+// RenderText executes the template and returns the text before any
+// conversion to the template format.
+func (p *Project) RenderText(t *TemplateDef) (string, error) {
 	var buf bytes.Buffer
 
 	if t == nil || t.Template == nil {
 		return "", fmt.Errorf("template not found")
 	}
+	if p.Strict && len(p.errors) > 0 {
+		return "", fmt.Errorf("project is not valid: %w", p.errors)
+	}
 
-	err = t.Template.Execute(&buf, p)
-	if err != nil {
-		return
+	if err := t.Template.Execute(&buf, p); err != nil {
+		return "", err
 	}
-	output = strings.Trim(regexp.MustCompile("\r\n[\r\n]+").ReplaceAllString(buf.String(), "\r\n\r\n"), "\r\n")
+	return strings.Trim(regexp.MustCompile("\r\n[\r\n]+").ReplaceAllString(buf.String(), "\r\n\r\n"), "\r\n"), nil
+}
 
-	if t.Format == "docx" {
-		f := converter.File{}
-		tmpFile, _ := f.TempFile("output.docx")
-		var fname, fref string
-		if fname, err = f.WriteToTempFile("output.md", []byte(output)); err != nil {
-			return
+func (p *Project) GetTemplateDef(name string) *TemplateDef {
+	// templates sharing a name across languages resolve to the current one
+	for _, t := range p.Templates() {
+		if t.Name == name {
+			return t
 		}
+	}
+	for _, t := range p.TemplateDefs {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
 
-		args := make([]string, 0)
-		args = append(args, "-f markdown+pipe_tables", "--from=markdown", "--to=docx",
-			"--columns=43", "--wrap=preserve", "--output="+tmpFile)
-
-		if t.ReferenceDoc != "" {
-			var refBytes []byte
-			var ok bool
-			refBytes, ok = p.Loader.Get(t.ReferenceDoc)
-			if ok {
-				if fref, err = f.WriteToTempFile("refdoc.docx", refBytes); err != nil {
-					return
-				}
-				args = append(args, "--reference-doc="+fref)
+// GetFeature returns the feature with the given tag, searching the whole
+// feature tree, or nil if there is none.
+func (p *Project) GetFeature(tag string) Feature {
+	var find func(features []Feature) Feature
+	find = func(features []Feature) Feature {
+		for _, f := range features {
+			if f.GetTag() == tag {
+				return f
+			}
+			if found := find(f.GetChildren()); found != nil {
+				return found
 			}
 		}
-		args = append(args, fname)
-		if _, err = converter.ExecCommand(time.Second*120, "pandoc", args...); err != nil {
-			return
-		}
-		if err = converter.FixDocxTableStyle(tmpFile, "output.docx"); err != nil {
-			return
-		}
-		return "output.docx", nil
-
+		return nil
 	}
-
-	return
+	return find(p.Features)
 }
 
 func (p *Project) Evaluate() string {
@@ -410,7 +601,7 @@ func (p *Project) Evaluate() string {
 		return ""
 	}
 
-	output, _ := p.Render(p.TemplateDefs[0])
+	output, _ := p.RenderText(p.TemplateDefs[0])
 	return output
 }
 
@@ -430,6 +621,13 @@ func (p *Project) SaveProjectAs(filename string) error {
 		//return saveToFile(Settings.StoragePath(), data)
 		return err
 	}
+	audit, err := json.Marshal(p.audit)
+	if err != nil {
+		return err
+	}
+	if err := p.Loader.Set(AUDIT_FILE, audit); err != nil {
+		return err
+	}
 	p.ProjectFile = filename
 	p.SetDirty(false)
 	return p.Loader.SaveAs(filename)
@@ -439,7 +637,13 @@ func (p *Project) ExportData() ProjectExport {
 	export := ProjectExport{Tags: make([]string, 0), Values: make(map[string]any)}
 
 	p.Validate("")
-	for t, v := range p.Tags {
+	values := make(map[string]any)
+	for _, f := range p.Features {
+		for _, t := range f.ApplicableFeatures() {
+			values[t.GetTag()] = t.GetValue()
+		}
+	}
+	for t, v := range values {
 		switch value := v.(type) {
 		case bool:
 			export.Tags = append(export.Tags, t)
@@ -450,10 +654,29 @@ func (p *Project) ExportData() ProjectExport {
 	return export
 }
 
+// LoadProjectData replaces the answers with the exported ones, recording in
+// the audit log those changed. The changes made before cannot be undone
+// anymore.
 func (p *Project) LoadProjectData(export ProjectExport) error {
+	before := p.answers()
+	// only the answers changed in the end are recorded
+	loading := p.loading
+	p.loading = true
+	err := p.ResetFeatures()
+	if err == nil {
+		err = p.setProjectData(export)
+	}
+	p.loading = loading
+	if err != nil {
+		return err
+	}
+	p.recordAnswers(before)
+	p.undo, p.redo = nil, nil
+	p.SetDirty(false)
+	return nil
+}
 
-	p.ResetFeatures()
-
+func (p *Project) setProjectData(export ProjectExport) error {
 	for k, v := range export.Values {
 		if err := p.SetFeature(k, v); err != nil {
 			return err
@@ -465,11 +688,8 @@ func (p *Project) LoadProjectData(export ProjectExport) error {
 			return err
 		}
 	}
-	if _, err := p.Validate(""); err != nil {
-		return err
-	}
-	p.SetDirty(false)
-	return nil
+	_, err := p.Validate("")
+	return err
 }
 
 func (p *Project) LoadProjectDataFromFile(filename string) error {
@@ -480,14 +700,21 @@ func (p *Project) LoadProjectDataFromFile(filename string) error {
 		return fmt.Errorf("file %s not found", filename)
 	}
 
-	err := json.Unmarshal(data, &export)
-	if err != nil {
+	if err := UnmarshalValues(data, &export); err != nil {
 		return err
 	}
 
 	return p.LoadProjectData(export)
 }
 
+// UnmarshalValues decodes answers keeping the numbers as written, so that
+// decimals do not go through float64.
+func UnmarshalValues(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
 func (p *Project) SetDirty(b bool) {
 	p.isDirty = b
 }
@@ -496,27 +723,48 @@ func (p *Project) Dirty() bool {
 	return p.isDirty
 }
 
-func (p *Project) ResetFeatures() {
+// ResetFeatures clears the answers, starting a new project. The answers
+// cleared are recorded in the audit log.
+func (p *Project) ResetFeatures() error {
+	before := p.answers()
 	for k := range p.Tags {
 		delete(p.Tags, k)
 	}
 
 	for _, f := range p.Features {
 		for _, t := range f.ApplicableFeatures() {
+			var err error
 			switch t.GetValue().(type) {
 			case bool:
-				t.SetValue(false)
-			case int64:
-				t.SetValue(0)
+				err = t.SetValue(false)
 			case string:
-				t.SetValue("")
+				err = t.SetValue("")
 			default:
-				t.SetValue(nil)
+				err = t.SetValue(nil)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to reset %q: %v", t.GetTag(), err)
 			}
 		}
 	}
+	p.recordAnswers(before)
 	p.ProjectFile = ""
+	p.undo, p.redo = nil, nil
 	p.SetDirty(false)
+	return nil
+}
+
+// ApplyDefaults gives the features their default values, recording the
+// answers changed in the audit log.
+func (p *Project) ApplyDefaults() error {
+	before := p.answers()
+	for _, f := range p.Features {
+		if err := f.ApplyDefaults(); err != nil {
+			return err
+		}
+	}
+	p.recordAnswers(before)
+	return nil
 }
 
 func (p *Project) GetValue() any {
@@ -540,7 +788,9 @@ func (p *Project) SetValue(value any) error {
 		for k, v := range t {
 			for _, f := range p.Features {
 				if f.GetTag() == k {
-					f.SetValue(v)
+					if err := f.SetValue(v); err != nil {
+						return fmt.Errorf("failed to set %q: %v", k, err)
+					}
 					break
 				}
 			}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"terra9.it/checkmate/core/converter"
+)
+
+// Report is a rendered template.
+type Report struct {
+	// Filename is the template name with the extension of the format
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Reader returns a reader of the report data.
+func (r *Report) Reader() io.Reader {
+	return bytes.NewReader(r.Data)
+}
+
+type reportFormat struct {
+	ext         string
+	contentType string
+}
+
+// reportFormats maps the template formats to their files, "" standing for
+// the templates rendering markdown text.
+var reportFormats = map[string]reportFormat{
+	"":     {"md", "text/markdown; charset=utf-8"},
+	"md":   {"md", "text/markdown; charset=utf-8"},
+	"docx": {"docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	"pdf":  {"pdf", "application/pdf"},
+	"html": {"html", "text/html; charset=utf-8"},
+}
+
+// Render renders a template to a report, in memory. Render does not change
+// the project, so a project may render several reports at once; ctx stops
+// the rendering between the template execution and the conversion.
+func (p *Project) Render(ctx context.Context, t *TemplateDef) (*Report, error) {
+	format := t.Format
+	if _, ok := reportFormats[format]; !ok {
+		format = ""
+	}
+	return p.RenderFormat(ctx, t, format)
+}
+
+// RenderFormat renders a template to a report in the given format rather
+// than in the one of the template: md for the markdown text, docx, pdf or
+// html.
+func (p *Project) RenderFormat(ctx context.Context, t *TemplateDef, format string) (*Report, error) {
+	if _, ok := reportFormats[format]; !ok {
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	text, err := p.RenderText(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch format {
+	case "docx":
+		data, err = p.renderDocx(t, text)
+	case "pdf":
+		data, err = p.renderPdf(t, text)
+	case "html":
+		data, err = p.renderHtml(t, text)
+	default:
+		data = []byte(text)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{
+		Filename:    t.Name + "." + reportFormats[format].ext,
+		ContentType: reportFormats[format].contentType,
+		Data:        data,
+	}, nil
+}
+
+// renderDocx converts the text of a docx template, styled after its
+// reference doc.
+func (p *Project) renderDocx(t *TemplateDef, text string) ([]byte, error) {
+	options := converter.DocxOptions{TableStyle: t.TableStyle}
+	if options.TableStyle == "" {
+		options.TableStyle = DOCX_TABLE_STYLE
+	}
+	if t.ReferenceDoc != "" {
+		if refBytes, ok := p.Loader.Get(t.ReferenceDoc); ok {
+			options.ReferenceDoc = refBytes
+		}
+	}
+	return converter.MarkdownToDocx(text, options)
+}
+
+func (p *Project) renderPdf(t *TemplateDef, text string) ([]byte, error) {
+	options := converter.PdfOptions{
+		Header: t.Header,
+		Footer: t.Footer,
+		Styles: t.Styles,
+	}
+	if t.Logo != "" {
+		logo, ok := p.Loader.Get(t.Logo)
+		if !ok {
+			return nil, fmt.Errorf("file %s not found", t.Logo)
+		}
+		options.Logo = logo
+	}
+	return converter.MarkdownToPdf(text, options)
+}
+
+// renderHtml converts the text of an html template to a standalone page.
+func (p *Project) renderHtml(t *TemplateDef, text string) ([]byte, error) {
+	options := converter.HtmlOptions{
+		Title:  p.Name,
+		Lang:   p.Language(),
+		Styles: t.Styles,
+	}
+	if t.Stylesheet != "" {
+		css, ok := p.Loader.Get(t.Stylesheet)
+		if !ok {
+			return nil, fmt.Errorf("file %s not found", t.Stylesheet)
+		}
+		options.Stylesheet = string(css)
+	}
+	return converter.MarkdownToHtml(text, options)
+}
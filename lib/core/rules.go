@@ -0,0 +1,167 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gterranova/go-bexpr"
+)
+
+const (
+	RULE_REQUIRED   = "required"
+	RULE_MIN_LENGTH = "min_length"
+	RULE_MAX_LENGTH = "max_length"
+	RULE_MIN        = "min"
+	RULE_MAX        = "max"
+	RULE_PATTERN    = "pattern"
+	RULE_VALID_IF   = "valid_if"
+
+	RULE_MIN_CHOICES = "min_choices"
+	RULE_MAX_CHOICES = "max_choices"
+)
+
+// Rules are the validation rules a feature may declare. Rules never stop a
+// value from being set: Project.Validate checks them on the enabled
+// features and collects the failures, see Project.Errors.
+//
+// Empty values (no value, an empty string, no choices) are only checked by
+// required and valid_if; zero is a number like any other. Messages overrides the default message
+// of a rule, Message is the one reported when valid_if is false.
+type Rules struct {
+	Required  bool              `json:"required,omitempty"`
+	MinLength *int              `json:"min_length,omitempty"`
+	MaxLength *int              `json:"max_length,omitempty"`
+	Min       *float64          `json:"min,omitempty"`
+	Max       *float64          `json:"max,omitempty"`
+	Pattern   string            `json:"pattern,omitempty"`
+	ValidIf   string            `json:"valid_if,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Messages  map[string]string `json:"messages,omitempty"`
+
+	patternRegexp  *regexp.Regexp `json:"-" bexpr:"-"`
+	validEvaluator *Evaluator     `json:"-" bexpr:"-"`
+}
+
+// ValidationError is a rule failed by the value of a tag.
+type ValidationError struct {
+	Tag     string `json:"tag"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Tag, e.Message)
+}
+
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ByTag returns the messages of the errors grouped by tag.
+func (e ValidationErrors) ByTag() map[string][]string {
+	tags := make(map[string][]string)
+	for _, err := range e {
+		tags[err.Tag] = append(tags[err.Tag], err.Message)
+	}
+	return tags
+}
+
+func isEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func (r *Rules) compile() error {
+	r.patternRegexp = nil
+	r.validEvaluator = nil
+	if r.Pattern != "" {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", r.Pattern, err)
+		}
+		r.patternRegexp = re
+	}
+	if r.ValidIf != "" {
+		eval, err := NewEvaluator(r.ValidIf)
+		if err != nil {
+			return fmt.Errorf("failed to create evaluator for expression %q: %v", r.ValidIf, err)
+		}
+		r.validEvaluator = eval
+	}
+	return nil
+}
+
+func (r *Rules) message(rule string, format string, args ...any) string {
+	if m, ok := r.Messages[rule]; ok {
+		return m
+	}
+	if rule == RULE_VALID_IF && r.Message != "" {
+		return r.Message
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// check returns the rules failed by value, nil when unanswered, a string,
+// an int64 or a list of choices; i is the datum the valid_if expression is
+// evaluated on.
+func (r *Rules) check(tag string, value any, i any) ValidationErrors {
+	errs := make(ValidationErrors, 0)
+	fail := func(rule string, format string, args ...any) {
+		errs = append(errs, &ValidationError{Tag: tag, Rule: rule, Message: r.message(rule, format, args...)})
+	}
+
+	empty := isEmptyValue(value)
+	if r.Required && empty {
+		fail(RULE_REQUIRED, "a value is required")
+	}
+	if !empty {
+		switch v := value.(type) {
+		case string:
+			length := utf8.RuneCountInString(v)
+			if r.MinLength != nil && length < *r.MinLength {
+				fail(RULE_MIN_LENGTH, "must be at least %d characters long", *r.MinLength)
+			}
+			if r.MaxLength != nil && length > *r.MaxLength {
+				fail(RULE_MAX_LENGTH, "must be at most %d characters long", *r.MaxLength)
+			}
+			if r.patternRegexp != nil && !r.patternRegexp.MatchString(v) {
+				fail(RULE_PATTERN, "does not match the pattern %q", r.Pattern)
+			}
+		case int64:
+			if r.Min != nil && float64(v) < *r.Min {
+				fail(RULE_MIN, "must be at least %v", *r.Min)
+			}
+			if r.Max != nil && float64(v) > *r.Max {
+				fail(RULE_MAX, "must be at most %v", *r.Max)
+			}
+		}
+	}
+	if r.validEvaluator != nil {
+		// a broken expression is reported as such, not as an invalid value
+		result, err := r.validEvaluator.Evaluate(i)
+		if err != nil {
+			errs = append(errs, &ValidationError{Tag: tag, Rule: RULE_VALID_IF,
+				Message: fmt.Sprintf("failed to run evaluation of expression %q: %v", r.ValidIf, err)})
+		} else if b, _ := bexpr.CoerceBool(result); !b {
+			fail(RULE_VALID_IF, "is not valid")
+		}
+	}
+	return errs
+}
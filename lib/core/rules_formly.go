@@ -0,0 +1,58 @@
+package core
+
+// formly names of the rules, as used in props and validation messages
+var formlyRules = map[string]string{
+	RULE_REQUIRED:   "required",
+	RULE_MIN_LENGTH: "minLength",
+	RULE_MAX_LENGTH: "maxLength",
+	RULE_MIN:        "min",
+	RULE_MAX:        "max",
+	RULE_PATTERN:    "pattern",
+}
+
+// formlyConfig adds the rules to props and returns the formlyConfig of the
+// feature, nil when there is nothing to configure.
+func (r *Rules) formlyConfig(props map[string]any) map[string]any {
+	if r.Required {
+		props["required"] = true
+	}
+	if r.MinLength != nil {
+		props["minLength"] = *r.MinLength
+	}
+	if r.MaxLength != nil {
+		props["maxLength"] = *r.MaxLength
+	}
+	if r.Min != nil {
+		props["min"] = *r.Min
+	}
+	if r.Max != nil {
+		props["max"] = *r.Max
+	}
+	if r.Pattern != "" {
+		props["pattern"] = r.Pattern
+	}
+
+	config := make(map[string]any)
+	if len(props) > 0 {
+		config["props"] = props
+	}
+	messages := make(map[string]string)
+	for rule, m := range r.Messages {
+		if name, ok := formlyRules[rule]; ok {
+			messages[name] = m
+		}
+	}
+	// valid_if is a server expression, the client only gets its message
+	if r.ValidIf != "" {
+		messages[RULE_VALID_IF] = r.message(RULE_VALID_IF, "is not valid")
+	}
+	if len(messages) > 0 {
+		config["validation"] = map[string]any{
+			"messages": messages,
+		}
+	}
+	if len(config) == 0 {
+		return nil
+	}
+	return config
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRulesCheckZero(t *testing.T) {
+	min := 1.0
+	r := &Rules{Required: true, Min: &min}
+	errs := r.check("n", int64(0), nil)
+	if len(errs) != 1 || errs[0].Rule != RULE_MIN {
+		t.Errorf("0 with required and min 1: got %v, want the min rule only", errs)
+	}
+
+	r = &Rules{Required: true}
+	if errs := r.check("n", int64(0), nil); len(errs) != 0 {
+		t.Errorf("0 is not accepted as a required value: %v", errs)
+	}
+	for _, empty := range []any{nil, "", []any{}} {
+		if errs := r.check("n", empty, nil); len(errs) != 1 || errs[0].Rule != RULE_REQUIRED {
+			t.Errorf("%#v: got %v, want the required rule", empty, errs)
+		}
+	}
+}
+
+func TestRulesFormlyKeepsValidIfOnTheServer(t *testing.T) {
+	r := &Rules{ValidIf: "tags.n > 2", Message: "too small"}
+	data, err := json.Marshal(r.formlyConfig(map[string]any{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "tags.n") || strings.Contains(string(data), "validators") {
+		t.Errorf("the valid_if expression is sent to the client: %s", data)
+	}
+	if !strings.Contains(string(data), `"messages":{"valid_if":"too small"}`) {
+		t.Errorf("the valid_if message is not sent to the client: %s", data)
+	}
+}
+
+func TestRequiredNumber(t *testing.T) {
+	p := newTestProject(t, `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+		"power": {"type": "number", "tag": "power", "title": "Power", "required": true, "min": 0}}}]}`, nil)
+	rules := func() []string {
+		list := make([]string, 0)
+		for _, err := range p.Errors() {
+			list = append(list, err.Rule)
+		}
+		return list
+	}
+	if got := rules(); len(got) != 1 || got[0] != RULE_REQUIRED {
+		t.Errorf("unanswered power: got %v, want required", got)
+	}
+	mustSet(t, p, "power", int64(-5))
+	if got := rules(); len(got) != 1 || got[0] != RULE_MIN {
+		t.Errorf("negative power: got %v, want min", got)
+	}
+	mustSet(t, p, "power", int64(0))
+	if got := rules(); len(got) != 0 {
+		t.Errorf("power 0: got %v, want no errors", got)
+	}
+	mustSet(t, p, "power", nil)
+	if got := rules(); len(got) != 1 || got[0] != RULE_REQUIRED {
+		t.Errorf("cleared power: got %v, want required", got)
+	}
+}
+
+func TestValidIfErrorIsReported(t *testing.T) {
+	r := &Rules{ValidIf: "tags.b * 2 > 1", Message: "too small"}
+	if err := r.compile(); err != nil {
+		t.Fatal(err)
+	}
+	errs := r.check("n", int64(1), map[string]any{"tags": map[string]any{"b": true}})
+	if len(errs) != 1 || errs[0].Rule != RULE_VALID_IF || !strings.Contains(errs[0].Message, "tags.b * 2 > 1") {
+		t.Errorf("got %v, want the evaluation error of valid_if", errs)
+	}
+	errs = r.check("n", int64(1), map[string]any{"tags": map[string]any{"b": int64(0)}})
+	if len(errs) != 1 || errs[0].Message != "too small" {
+		t.Errorf("got %v, want the valid_if message", errs)
+	}
+}
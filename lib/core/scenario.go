@@ -0,0 +1,223 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SCENARIO_DIR      = "tests"
+	SCENARIO_MAX_DIFF = 20
+)
+
+// Scenario is a regression test stored in the package tests directory. The
+// input answers are loaded into the project and the resulting tags, values,
+// disabled features and rendered templates are compared with the expected
+// ones. A yaml file may hold several scenarios separated by `---`.
+type Scenario struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description,omitempty"`
+	Input       ProjectExport  `yaml:"input"`
+	Expect      ScenarioExpect `yaml:"expect"`
+	Filename    string         `yaml:"-"`
+}
+
+type ScenarioExpect struct {
+	// tags that must be set
+	Tags []string `yaml:"tags,omitempty"`
+	// tags that must not be set
+	Absent []string `yaml:"absent,omitempty"`
+	// tag values, compared by their textual representation
+	Values   map[string]any `yaml:"values,omitempty"`
+	Disabled []string       `yaml:"disabled,omitempty"`
+	Enabled  []string       `yaml:"enabled,omitempty"`
+	// template name to snapshot file of the rendered text
+	Render map[string]string `yaml:"render,omitempty"`
+}
+
+type ScenarioResult struct {
+	Scenario *Scenario
+	Failures []string
+	Err      error
+}
+
+func (r *ScenarioResult) Passed() bool {
+	return r.Err == nil && len(r.Failures) == 0
+}
+
+func (r *ScenarioResult) fail(format string, a ...any) {
+	r.Failures = append(r.Failures, fmt.Sprintf(format, a...))
+}
+
+// LoadScenarios reads every .yaml/.yml file in the package tests directory.
+func (p *Project) LoadScenarios() ([]*Scenario, error) {
+	lister, ok := p.Loader.(ResourceLister)
+	if !ok {
+		return nil, fmt.Errorf("loader cannot list the %s directory", SCENARIO_DIR)
+	}
+
+	scenarios := make([]*Scenario, 0)
+	for _, filename := range lister.List(SCENARIO_DIR) {
+		if ext := path.Ext(filename); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		data, ok := p.Loader.Get(filename)
+		if !ok {
+			return nil, fmt.Errorf("file %s not found", filename)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		for count := 1; ; count++ {
+			s := &Scenario{}
+			if err := decoder.Decode(s); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("cannot read %s: %v", filename, err)
+			}
+			s.Filename = filename
+			if s.Name == "" {
+				s.Name = fmt.Sprintf("%s#%d", path.Base(filename), count)
+			}
+			scenarios = append(scenarios, s)
+		}
+	}
+	return scenarios, nil
+}
+
+// RunScenarios runs all the package scenarios. The answers of the project
+// are restored after each one, and the runs are not recorded in the audit
+// log nor in the undo stacks.
+func (p *Project) RunScenarios() ([]*ScenarioResult, error) {
+	scenarios, err := p.LoadScenarios()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*ScenarioResult, 0, len(scenarios))
+	for _, s := range scenarios {
+		err := p.keepingAnswers(func() error {
+			results = append(results, p.RunScenario(s))
+			return nil
+		})
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// RunScenario loads the scenario input into the project and compares the
+// outcome with the expectations.
+func (p *Project) RunScenario(s *Scenario) *ScenarioResult {
+	r := &ScenarioResult{Scenario: s, Failures: make([]string, 0)}
+
+	if r.Err = p.LoadProjectData(s.Input); r.Err != nil {
+		return r
+	}
+
+	for _, tag := range s.Expect.Tags {
+		if v, ok := p.Tags[tag]; !ok || v == false {
+			r.fail("tag %q is not set", tag)
+		}
+	}
+	for _, tag := range s.Expect.Absent {
+		if v, ok := p.Tags[tag]; ok && v != false {
+			r.fail("tag %q is set", tag)
+		}
+	}
+	for tag, want := range s.Expect.Values {
+		got, ok := p.Tags[tag]
+		if !ok {
+			r.fail("tag %q has no value, expected %v", tag, want)
+		} else if fmt.Sprint(got) != fmt.Sprint(want) {
+			r.fail("tag %q is %v, expected %v", tag, got, want)
+		}
+	}
+	for _, tag := range s.Expect.Disabled {
+		if f := p.GetFeature(tag); f == nil {
+			r.fail("feature %q not found", tag)
+		} else if !f.IsDisabled() {
+			r.fail("feature %q is enabled", tag)
+		}
+	}
+	for _, tag := range s.Expect.Enabled {
+		if f := p.GetFeature(tag); f == nil {
+			r.fail("feature %q not found", tag)
+		} else if f.IsDisabled() {
+			r.fail("feature %q is disabled", tag)
+		}
+	}
+	for name, snapshot := range s.Expect.Render {
+		t := p.GetTemplateDef(name)
+		if t == nil {
+			r.fail("template %q not found", name)
+			continue
+		}
+		want, ok := p.Loader.Get(snapshot)
+		if !ok {
+			r.fail("snapshot %s of template %q not found", snapshot, name)
+			continue
+		}
+		got, err := p.RenderText(t)
+		if err != nil {
+			r.fail("cannot render template %q: %v", name, err)
+			continue
+		}
+		if diff := diffLines(string(want), got); len(diff) > 0 {
+			r.fail("template %q differs from %s:\n%s", name, snapshot, strings.Join(diff, "\n"))
+		}
+	}
+	return r
+}
+
+func snapshotLines(text string) []string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	return lines
+}
+
+// diffLines returns the lines removed from (-) and added to (+) the
+// expected text, using the longest common subsequence of the two.
+func diffLines(expected, actual string) []string {
+	a, b := snapshotLines(expected), snapshotLines(actual)
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]string, 0)
+	i, j := 0, 0
+	for (i < len(a) || j < len(b)) && len(diff) < SCENARIO_MAX_DIFF {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			diff = append(diff, fmt.Sprintf("%4d + %s", j+1, b[j]))
+			j++
+		default:
+			diff = append(diff, fmt.Sprintf("%4d - %s", i+1, a[i]))
+			i++
+		}
+	}
+	return diff
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const scenarios = `name: a chosen
+input:
+  values:
+    a: true
+    s: x
+expect:
+  tags: [a, c]
+  values:
+    s: x
+  render:
+    t: tests/a.md
+---
+input:
+  values:
+    s: y
+expect:
+  tags: [c]
+  absent: [a]
+  values:
+    s: x
+  render:
+    t: tests/a.md
+`
+
+const snapshot = `Intro
+# A
+A chosen
+# S
+Value x
+# End
+End.
+`
+
+func TestRunScenarios(t *testing.T) {
+	p := newTestProject(t, diffConfig, map[string]string{
+		"t.tmpl":       diffTemplate,
+		"tests/a.yaml": scenarios,
+		"tests/a.md":   snapshot,
+		"tests/a.txt":  "not a scenario",
+	})
+	mustSet(t, p, "s", "mine")
+	audit, changes := p.AuditLog(), p.Changes()
+	p.ProjectFile = "mine.json"
+
+	results, err := p.RunScenarios()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	if r := results[0]; !r.Passed() || r.Scenario.Name != "a chosen" {
+		t.Errorf("%s: unexpected failures %v, %v", r.Scenario.Name, r.Failures, r.Err)
+	}
+
+	r := results[1]
+	if r.Passed() || r.Scenario.Name != "a.yaml#2" {
+		t.Fatalf("%s: expected to fail", r.Scenario.Name)
+	}
+	failures := strings.Join(r.Failures, "\n")
+	for _, want := range []string{`tag "c" is not set`, `tag "s" is y, expected x`, `template "t" differs from tests/a.md`, "   3 - A chosen", "   3 + A not chosen"} {
+		if !strings.Contains(failures, want) {
+			t.Errorf("missing %q in the failures:\n%s", want, failures)
+		}
+	}
+	if strings.Contains(failures, `tag "a"`) {
+		t.Errorf("a is reported as set:\n%s", failures)
+	}
+
+	if got := p.GetFeature("s").GetValue(); got != "mine" {
+		t.Errorf("the answers are not restored, s is %v", got)
+	}
+	if !reflect.DeepEqual(p.AuditLog(), audit) || len(p.Changes()) != len(changes) {
+		t.Errorf("the scenarios are recorded in the audit log or the undo stack")
+	}
+	if !p.Dirty() || p.ProjectFile != "mine.json" {
+		t.Errorf("got dirty %v and file %q, want them kept", p.Dirty(), p.ProjectFile)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc\n", "a\r\nc  \nd\n")
+	want := []string{"   2 - b", "   3 + d"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -6,51 +6,74 @@ import (
 	"github.com/gterranova/go-bexpr"
 )
 
+// Select is a choice among its options, each one exposed as a boolean tag.
+//
+// A Multiple select also exposes the list of the selected option tags under
+// its own tag, so that expressions can check `tags.x contains "y"` or
+// `"y" in tags.x`. MaxChoices is enforced when the value is set,
+// MinChoices is reported as a validation rule.
 type Select struct {
 	Type       string `json:"type"`
 	Title      string `json:"title"`
 	Value      any    `json:"value" bexpr:"value"`
 	Disabled   bool   `json:"disabled,omitempty"`
 	Default    any    `json:"default,omitempty"`
+	Multiple   bool   `json:"multiple,omitempty"`
+	MinChoices int    `json:"min_choices,omitempty"`
+	MaxChoices int    `json:"max_choices,omitempty"`
 	Tag        string `json:"tag,omitempty"`
 	Condition  string `json:"condition,omitempty"`
 	DisabledOn string `json:"disabled_on,omitempty"`
 	InfoUrl    string `json:"info_url,omitempty"`
+	Rules      `bexpr:"-"`
 
-	valueEvaluator    *bexpr.Evaluator `json:"-" bexpr:"-"`
-	disabledEvaluator *bexpr.Evaluator `json:"-" bexpr:"-"`
+	valueEvaluator    *Evaluator `json:"-" bexpr:"-"`
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
 
 	//Properties []any `json:"properties" bexpr:"properties"`
 	Enum []*Option `json:"enum" bexpr:"enum"`
 }
 
 func (feature *Select) Validate(tag string, i any) (changed bool, err error) {
+	var subchanged bool
+
 	if feature.Tag != "" && feature.Tag == tag {
 		return
 	}
 
 	for _, f := range feature.GetChildren() {
-		subchanged, _ := f.Validate(tag, i)
+		subchanged, _ = f.Validate(tag, i)
 		changed = changed || subchanged
 	}
+	if subchanged, err = feature.EvaluateCondition(i); err != nil {
+		return
+	}
+	changed = changed || subchanged
+	subchanged, err = feature.EvaluateDisabledOn(i)
+	return changed || subchanged, err
+}
+
+func (feature *Select) EvaluateCondition(i any) (changed bool, err error) {
 	if feature.Tag != "" && feature.valueEvaluator != nil {
-		result, eval_err := feature.valueEvaluator.Evaluate(i)
-		if eval_err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, eval_err)
+		result, err := feature.valueEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, err)
 		}
 		b, _ := bexpr.CoerceBool(result)
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Value != b
+		changed = feature.Value != b
 		feature.Value = b
 	}
+	return
+}
+
+func (feature *Select) EvaluateDisabledOn(i any) (changed bool, err error) {
 	if len(feature.DisabledOn) > 0 {
-		result, eval_err := feature.disabledEvaluator.Evaluate(i)
-		if eval_err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, eval_err)
+		result, err := feature.disabledEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
 		}
 		b, _ := bexpr.CoerceBool(result)
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Disabled != b
+		changed = feature.Disabled != b
 		feature.Disabled = b
 	}
 	return
@@ -59,7 +82,7 @@ func (feature *Select) Validate(tag string, i any) (changed bool, err error) {
 func (feature *Select) ApplyDefaults() error {
 	// apply defaults
 	if feature.Condition != "" {
-		eval, err := bexpr.CreateEvaluator(feature.Condition)
+		eval, err := NewEvaluator(feature.Condition)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
 		} else {
@@ -68,7 +91,7 @@ func (feature *Select) ApplyDefaults() error {
 	}
 
 	if feature.DisabledOn != "" {
-		eval, err := bexpr.CreateEvaluator(feature.DisabledOn)
+		eval, err := NewEvaluator(feature.DisabledOn)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
 		} else {
@@ -76,21 +99,22 @@ func (feature *Select) ApplyDefaults() error {
 		}
 	}
 
-	for _, f := range feature.GetChildren() {
-		if f.GetTag() == feature.Default.(string) {
-			if err := f.SetValue(true); err != nil {
-				return err
-			}
-		} else {
-			if err := f.SetValue(false); err != nil {
-				return err
-			}
-		}
+	if err := feature.Rules.compile(); err != nil {
+		return err
 	}
-	return nil
+
+	return feature.SetValue(feature.Default)
 }
 
 func (feature *Select) Set(tag string, value any) error {
+	if b, ok := value.(bool); ok && b && feature.Multiple && feature.MaxChoices > 0 {
+		selected := feature.selected()
+		for _, option := range feature.Enum {
+			if option.Tag == tag && !option.Value && len(selected) >= feature.MaxChoices {
+				return fmt.Errorf("%q allows at most %d choices", feature.Tag, feature.MaxChoices)
+			}
+		}
+	}
 	for _, f := range feature.GetChildren() {
 		if err := f.Set(tag, value); err != nil {
 			return err
@@ -106,6 +130,9 @@ func (feature *Select) Set(tag string, value any) error {
 
 func (feature *Select) ApplicableFeatures() []Feature {
 	f := make([]Feature, 0)
+	if feature.Multiple && !feature.Disabled && feature.Tag != "" {
+		f = append(f, feature)
+	}
 	/*
 		if !feature.Disabled {
 			switch t := feature.Value.(type) {
@@ -136,6 +163,9 @@ func (feature *Select) GetChildren() []Feature {
 }
 
 func (feature *Select) GetValue() any {
+	if feature.Multiple {
+		return feature.selected()
+	}
 	for _, option := range feature.Enum {
 		if option.GetValue().(bool) {
 			//fmt.Printf("getting select %s = %v (%T)\n", option.GetTag(), option.GetValue(), option.GetValue())
@@ -145,17 +175,52 @@ func (feature *Select) GetValue() any {
 	return nil
 }
 
+// selected returns the tags of the selected options.
+func (feature *Select) selected() []string {
+	tags := make([]string, 0)
+	for _, option := range feature.Enum {
+		if option.Value {
+			tags = append(tags, option.Tag)
+		}
+	}
+	return tags
+}
+
+// SetValue selects the option with the given tag, or the options in the
+// given list for Multiple selects. A nil value clears the selection.
 func (feature *Select) SetValue(value any) error {
+	selected := make(map[string]bool)
 	switch t := value.(type) {
+	case nil:
 	case string:
-		for _, option := range feature.Enum {
-			if err := option.SetValue(option.GetTag() == t); err != nil {
-				return err
+		selected[t] = t != ""
+	case []string:
+		for _, tag := range t {
+			selected[tag] = true
+		}
+	case []any:
+		for _, v := range t {
+			tag, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("unknown type %T for %v", v, v)
 			}
+			selected[tag] = true
 		}
 	default:
 		return fmt.Errorf("unknown type %T for %v", t, t)
 	}
+
+	if !feature.Multiple && len(selected) > 1 {
+		return fmt.Errorf("%q allows a single choice", feature.Tag)
+	}
+	if feature.MaxChoices > 0 && len(selected) > feature.MaxChoices {
+		return fmt.Errorf("%q allows at most %d choices", feature.Tag, feature.MaxChoices)
+	}
+	for _, option := range feature.Enum {
+		if err := option.SetValue(selected[option.Tag]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 func (feature *Select) GetTag() string {
@@ -180,6 +245,33 @@ func (feature *Select) GetInfoUrl() string {
 	return feature.InfoUrl
 }
 
+func (feature *Select) GetCondition() string {
+	return feature.Condition
+}
+
+func (feature *Select) GetDisabledOn() string {
+	return feature.DisabledOn
+}
+
+func (feature *Select) GetRules() *Rules {
+	return &feature.Rules
+}
+
+// CheckRules implements FeatureWithRules.
+func (feature *Select) CheckRules(i any) ValidationErrors {
+	errs := feature.Rules.check(feature.Tag, feature.GetValue(), i)
+	count := len(feature.selected())
+	if feature.MinChoices > 0 && count < feature.MinChoices {
+		errs = append(errs, &ValidationError{Tag: feature.Tag, Rule: RULE_MIN_CHOICES,
+			Message: feature.message(RULE_MIN_CHOICES, "at least %d choices are required", feature.MinChoices)})
+	}
+	if feature.MaxChoices > 0 && count > feature.MaxChoices {
+		errs = append(errs, &ValidationError{Tag: feature.Tag, Rule: RULE_MAX_CHOICES,
+			Message: feature.message(RULE_MAX_CHOICES, "at most %d choices are allowed", feature.MaxChoices)})
+	}
+	return errs
+}
+
 func (feature *Select) UnmarshalJSON(bytes []byte) (err error) {
 	marshaller := &SelectFormly{feature}
 	return marshaller.UnmarshalJSON(bytes)
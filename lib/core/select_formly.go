@@ -2,6 +2,7 @@ package core
 
 import (
 	"encoding/json"
+	"strconv"
 )
 
 type SelectFormly struct {
@@ -11,7 +12,10 @@ type SelectFormly struct {
 type SelectProps struct {
 	Disabled     bool               `json:"disabled,omitempty"`
 	Multiple     bool               `json:"multiple,omitempty"`
-	DefaultValue string             `json:"defaultValue,omitempty"`
+	Required     bool               `json:"required,omitempty"`
+	MinChoices   int                `json:"min_choices,omitempty"`
+	MaxChoices   int                `json:"max_choices,omitempty"`
+	DefaultValue any                `json:"defaultValue,omitempty"`
 	HideDisabled bool               `json:"hide_disabled,omitempty"`
 	InfoUrl      string             `json:"info_url,omitempty"`
 	Options      []OptionLabelValue `json:"options,omitempty"`
@@ -67,16 +71,26 @@ func (feature *SelectFormly) MarshalJSON() ([]byte, error) {
 		foo.Enum = append(foo.Enum, opt.Tag)
 		props.Options = append(props.Options, OptionLabelValue{Label: opt.Title, Value: opt.Tag, Disabled: opt.Disabled})
 	}
-	if feature.Default != nil {
-		props.DefaultValue = feature.Default.(string)
+	if !isEmptyValue(feature.Default) {
+		props.DefaultValue = feature.Default
 	}
+	props.Multiple = feature.Multiple
+	props.Required = feature.Required
+	props.MinChoices = feature.MinChoices
+	props.MaxChoices = feature.MaxChoices
 
 	if feature.InfoUrl != "" {
 		props.InfoUrl = feature.InfoUrl
 	}
 
+	// validation messages and validators, the props are the select ones
+	if config := feature.Rules.formlyConfig(make(map[string]any)); config != nil {
+		for k, v := range config {
+			foo.Widget.FormlyConfig[k] = v
+		}
+	}
 	foo.Widget.FormlyConfig["type"] = "select"
-	foo.Widget.FormlyConfig["multiple"] = "false"
+	foo.Widget.FormlyConfig["multiple"] = strconv.FormatBool(feature.Multiple)
 	foo.Widget.FormlyConfig["props"] = props
 
 	valueBytes, err := json.Marshal(foo)
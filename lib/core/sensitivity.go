@@ -0,0 +1,277 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gterranova/go-bexpr/grammar"
+	"github.com/shopspring/decimal"
+)
+
+// SENSITIVITY_MAX_RANGES bounds the ranges found by Sensitivity, as tags
+// changing at most values tell nothing about thresholds.
+const SENSITIVITY_MAX_RANGES = 100
+
+// Sensitivity tells how the tags of a project change with the value of a
+// numeric answer, the other answers staying as they are.
+type Sensitivity struct {
+	Tag string `json:"tag"`
+	// the values where some tag changes, the first of each range but the
+	// first one
+	Breakpoints []json.Number      `json:"breakpoints"`
+	Ranges      []SensitivityRange `json:"ranges"`
+}
+
+// SensitivityRange is a range of values of the answer giving the same tags.
+type SensitivityRange struct {
+	// bounds included, missing when the feature does not bound the values
+	From json.Number `json:"from,omitempty"`
+	To   json.Number `json:"to,omitempty"`
+	// the boolean tags changing from a range to another, false when unset
+	Tags map[string]bool `json:"tags"`
+}
+
+// numericAnswer is a Number or Decimal feature scanned by Sensitivity.
+type numericAnswer struct {
+	step     decimal.Decimal
+	min, max *decimal.Decimal
+	// value converts a point of the scan to a value of the feature
+	value func(v decimal.Decimal) any
+}
+
+func newNumericAnswer(f Feature) (*numericAnswer, error) {
+	if fe, ok := f.(FeatureWithExpressions); ok && fe.GetCondition() != "" {
+		return nil, fmt.Errorf("feature %s is computed by its condition", f.GetTag())
+	}
+	switch t := f.(type) {
+	case *Number:
+		a := &numericAnswer{step: decimal.NewFromInt(1), value: func(v decimal.Decimal) any {
+			return v.IntPart()
+		}}
+		if t.Rules.Min != nil {
+			min := decimal.NewFromFloat(*t.Rules.Min).Ceil()
+			a.min = &min
+		}
+		if t.Rules.Max != nil {
+			max := decimal.NewFromFloat(*t.Rules.Max).Floor()
+			a.max = &max
+		}
+		return a, nil
+	case *Decimal:
+		a := &numericAnswer{min: t.Min, max: t.Max, value: func(v decimal.Decimal) any {
+			return v
+		}}
+		// without a step nor a precision, the step is found by Sensitivity
+		switch {
+		case t.Step != nil && t.Step.IsPositive():
+			a.step = *t.Step
+		case t.Precision != nil:
+			a.step = decimal.New(1, -*t.Precision)
+		}
+		return a, nil
+	}
+	return nil, fmt.Errorf("feature %s is not a number", f.GetTag())
+}
+
+// finestStep returns the unit of the last decimal place of the numbers.
+func finestStep(numbers []decimal.Decimal) decimal.Decimal {
+	exp := int32(0)
+	for _, n := range numbers {
+		exp = min(exp, n.Exponent())
+	}
+	return decimal.New(1, exp)
+}
+
+// snap returns the point of the scan closest to v from below.
+func (a *numericAnswer) snap(v decimal.Decimal) decimal.Decimal {
+	return v.Div(a.step).Floor().Mul(a.step)
+}
+
+// Sensitivity finds the values of the Number or Decimal feature with the tag
+// where the other boolean tags change. The values are sampled around the
+// numbers found in the expressions depending on the tag, and the changes
+// found between two samples are located by bisection over Validate. The
+// answers of the project are restored afterwards.
+func (p *Project) Sensitivity(tag string) (*Sensitivity, error) {
+	f := p.GetFeature(tag)
+	if f == nil {
+		return nil, fmt.Errorf("feature %s not found", tag)
+	}
+	a, err := newNumericAnswer(f)
+	if err != nil {
+		return nil, err
+	}
+	current, err := ParseDecimal(f.GetValue())
+	if err != nil {
+		return nil, err
+	}
+	literals, err := p.comparedNumbers(tag)
+	if err != nil {
+		return nil, err
+	}
+	if a.step.IsZero() {
+		// as fine as the numbers the answer is compared with
+		numbers := append([]decimal.Decimal{current}, literals...)
+		for _, bound := range []*decimal.Decimal{a.min, a.max} {
+			if bound != nil {
+				numbers = append(numbers, *bound)
+			}
+		}
+		a.step = finestStep(numbers)
+	}
+
+	// the samples, within the bounds of the feature
+	points := []decimal.Decimal{a.snap(current), decimal.Zero}
+	for _, n := range literals {
+		n = a.snap(n)
+		points = append(points, n.Sub(a.step), n, n.Add(a.step))
+	}
+	lo, hi := slices.MinFunc(points, decimal.Decimal.Cmp), slices.MaxFunc(points, decimal.Decimal.Cmp)
+	if a.min != nil {
+		lo = a.snap(*a.min)
+		if lo.LessThan(*a.min) {
+			lo = lo.Add(a.step)
+		}
+	}
+	if a.max != nil {
+		hi = a.snap(*a.max)
+	}
+	points = slices.DeleteFunc(points, func(v decimal.Decimal) bool {
+		return v.LessThan(lo) || v.GreaterThan(hi)
+	})
+	points = append(points, lo, hi)
+	slices.SortFunc(points, decimal.Decimal.Cmp)
+	points = slices.CompactFunc(points, decimal.Decimal.Equal)
+
+	s := &Sensitivity{Tag: tag, Breakpoints: make([]json.Number, 0)}
+	starts := []decimal.Decimal{lo}
+	outcomes := make([]map[string]any, 0)
+	err = p.keepingAnswers(func() error {
+		outcome := func(v decimal.Decimal) (map[string]any, error) {
+			if err := p.SetFeature(tag, a.value(v)); err != nil {
+				return nil, err
+			}
+			tags := make(map[string]any)
+			for t, value := range p.Tags {
+				if b, ok := value.(bool); ok && b && t != tag {
+					tags[t] = true
+				}
+			}
+			return tags, nil
+		}
+
+		last, err := outcome(points[0])
+		if err != nil {
+			return err
+		}
+		outcomes = append(outcomes, last)
+		for i := 1; i < len(points); i++ {
+			from := points[i-1]
+			next, err := outcome(points[i])
+			if err != nil {
+				return err
+			}
+			// several changes may lie between two samples
+			for !reflect.DeepEqual(last, next) {
+				// the first value after from with a different outcome
+				below, above := from, points[i]
+				for above.Sub(below).GreaterThan(a.step) {
+					mid := a.snap(below.Add(above).Div(decimal.NewFromInt(2)))
+					tags, err := outcome(mid)
+					if err != nil {
+						return err
+					}
+					if reflect.DeepEqual(tags, last) {
+						below = mid
+					} else {
+						above = mid
+					}
+				}
+				if last, err = outcome(above); err != nil {
+					return err
+				}
+				starts = append(starts, above)
+				outcomes = append(outcomes, last)
+				from = above
+				if len(starts) > SENSITIVITY_MAX_RANGES {
+					return fmt.Errorf("the tags change at more than %d values of %s", SENSITIVITY_MAX_RANGES, tag)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// only the tags changing somewhere are reported
+	changing := make(map[string]bool)
+	for _, o := range outcomes[1:] {
+		for _, c := range diffValues(outcomes[0], o, nil) {
+			changing[c.Tag] = true
+		}
+	}
+	for i, start := range starts {
+		r := SensitivityRange{Tags: make(map[string]bool)}
+		if i > 0 || a.min != nil {
+			r.From = json.Number(start.String())
+		}
+		if i < len(starts)-1 {
+			r.To = json.Number(starts[i+1].Sub(a.step).String())
+		} else if a.max != nil {
+			r.To = json.Number(hi.String())
+		}
+		for t := range changing {
+			r.Tags[t] = outcomes[i][t] == true
+		}
+		if i > 0 {
+			s.Breakpoints = append(s.Breakpoints, r.From)
+		}
+		s.Ranges = append(s.Ranges, r)
+	}
+	return s, nil
+}
+
+// comparedNumbers returns the numbers found in the expressions depending on
+// the tag, directly or through the tags they produce: the thresholds of
+// the derived tags hint at the scale of the values to sample.
+func (p *Project) comparedNumbers(tag string) ([]decimal.Decimal, error) {
+	g, err := p.DependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+	numbers := make([]decimal.Decimal, 0)
+	depending := []string{tag}
+	visited := make(map[*evaluationNode]bool)
+	for i := 0; i < len(depending); i++ {
+		for _, n := range g.nodes {
+			if visited[n] || !slices.Contains(n.reads, depending[i]) {
+				continue
+			}
+			visited[n] = true
+			for _, t := range n.produces {
+				if !slices.Contains(depending, t) {
+					depending = append(depending, t)
+				}
+			}
+			if n.expression == "" {
+				continue
+			}
+			ast, err := ParseExpression(n.expression)
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range expressionValues(ast) {
+				if v.Type == grammar.ValueTypeReflect {
+					continue
+				}
+				if d, err := decimal.NewFromString(v.Raw); err == nil {
+					numbers = append(numbers, d)
+				}
+			}
+		}
+	}
+	return numbers, nil
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const derivedSensitivityConfig = `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+	"power": {"type": "decimal", "tag": "power", "title": "Power", "min": 0},
+	"double": {"type": "decimal", "tag": "double", "title": "Double", "condition": "tags.power * 2"},
+	"big": {"type": "checkbox", "tag": "big", "title": "Big", "condition": "tags.double >= 1000"}}}]}`
+
+func TestSensitivityThroughDerivedTags(t *testing.T) {
+	var results []*Sensitivity
+	for _, current := range []string{"100", "700"} {
+		p := newTestProject(t, derivedSensitivityConfig, nil)
+		mustSet(t, p, "power", json.Number(current))
+		s, err := p.Sensitivity("power")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []json.Number{"500"}; !reflect.DeepEqual(s.Breakpoints, want) {
+			t.Errorf("power %s: got breakpoints %v, want %v", current, s.Breakpoints, want)
+		}
+		if got := p.GetFeature("power").GetValue(); got != json.Number(current) {
+			t.Errorf("power %s: the answer is not restored, got %v", current, got)
+		}
+		results = append(results, s)
+	}
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("the result depends on the current answer: %+v, %+v", results[0], results[1])
+	}
+}
+
+func TestSensitivityRanges(t *testing.T) {
+	p := newTestProject(t, boundedDecimalConfig, nil)
+	s, err := p.Sensitivity("power")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SensitivityRange{
+		{From: "1", To: "49", Tags: map[string]bool{"big": false}},
+		{From: "50", To: "100", Tags: map[string]bool{"big": true}},
+	}
+	if !reflect.DeepEqual(s.Ranges, want) {
+		t.Errorf("got ranges %+v, want %+v", s.Ranges, want)
+	}
+}
+
+func TestSensitivityStepFollowsPrecision(t *testing.T) {
+	for precision, want := range map[string]json.Number{"": "2.56", `"precision": 1,`: "2.6", `"precision": 3,`: "2.551"} {
+		p := newTestProject(t, `{"name": "T", "features": [{"type": "checkform", "tag": "form", "title": "Form", "properties": {
+			"rate": {"type": "decimal", "tag": "rate", "title": "Rate", `+precision+` "min": 0},
+			"high": {"type": "checkbox", "tag": "high", "title": "High", "condition": "tags.rate > 2.55"}}}]}`, nil)
+		s, err := p.Sensitivity("rate")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(s.Breakpoints, []json.Number{want}) {
+			t.Errorf("%s: got breakpoints %v, want %v", precision, s.Breakpoints, want)
+		}
+	}
+}
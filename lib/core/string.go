@@ -16,30 +16,43 @@ type String struct {
 	Condition  string `json:"condition,omitempty"`
 	DisabledOn string `json:"disabled_on,omitempty"`
 	InfoUrl    string `json:"info_url,omitempty"`
+	Rules      `bexpr:"-"`
 
-	valueEvaluator    *bexpr.Evaluator `json:"-" bexpr:"-"`
-	disabledEvaluator *bexpr.Evaluator `json:"-" bexpr:"-"`
+	valueEvaluator    *Evaluator `json:"-" bexpr:"-"`
+	disabledEvaluator *Evaluator `json:"-" bexpr:"-"`
 }
 
 func (feature *String) Validate(tag string, i any) (changed bool, err error) {
+	var disabledChanged bool
+
+	if changed, err = feature.EvaluateCondition(i); err != nil {
+		return
+	}
+	disabledChanged, err = feature.EvaluateDisabledOn(i)
+	return changed || disabledChanged, err
+}
+
+func (feature *String) EvaluateCondition(i any) (changed bool, err error) {
 	if feature.Tag != "" && feature.valueEvaluator != nil {
-		result, eval_err := feature.valueEvaluator.Evaluate(i)
-		if eval_err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, eval_err)
+		result, err := feature.valueEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.Condition, err)
 		}
 		b, _ := result.(string)
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Value != b
+		changed = feature.Value != b
 		feature.Value = b
 	}
+	return
+}
+
+func (feature *String) EvaluateDisabledOn(i any) (changed bool, err error) {
 	if len(feature.DisabledOn) > 0 {
-		result, eval_err := feature.disabledEvaluator.Evaluate(i)
-		if eval_err != nil {
-			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, eval_err)
+		result, err := feature.disabledEvaluator.Evaluate(i)
+		if err != nil {
+			return changed, fmt.Errorf("failed to run evaluation of expression %q: %v", feature.DisabledOn, err)
 		}
 		b, _ := bexpr.CoerceBool(result)
-		//fmt.Printf("Result of expression %q evaluation: %t\n", expression, result)
-		changed = changed || feature.Disabled != b
+		changed = feature.Disabled != b
 		feature.Disabled = b
 	}
 	return
@@ -48,7 +61,7 @@ func (feature *String) Validate(tag string, i any) (changed bool, err error) {
 func (feature *String) ApplyDefaults() error {
 	// apply defaults
 	if feature.Condition != "" {
-		eval, err := bexpr.CreateEvaluator(feature.Condition)
+		eval, err := NewEvaluator(feature.Condition)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.Condition, err)
 		} else {
@@ -57,7 +70,7 @@ func (feature *String) ApplyDefaults() error {
 	}
 
 	if feature.DisabledOn != "" {
-		eval, err := bexpr.CreateEvaluator(feature.DisabledOn)
+		eval, err := NewEvaluator(feature.DisabledOn)
 		if err != nil {
 			return fmt.Errorf("failed to create evaluator for expression %q: %v", feature.DisabledOn, err)
 		} else {
@@ -65,6 +78,10 @@ func (feature *String) ApplyDefaults() error {
 		}
 	}
 
+	if err := feature.Rules.compile(); err != nil {
+		return err
+	}
+
 	return feature.SetValue(feature.Default)
 }
 
@@ -116,6 +133,23 @@ func (feature *String) GetInfoUrl() string {
 	return feature.InfoUrl
 }
 
+func (feature *String) GetCondition() string {
+	return feature.Condition
+}
+
+func (feature *String) GetDisabledOn() string {
+	return feature.DisabledOn
+}
+
+func (feature *String) GetRules() *Rules {
+	return &feature.Rules
+}
+
+// CheckRules implements FeatureWithRules.
+func (feature *String) CheckRules(i any) ValidationErrors {
+	return feature.Rules.check(feature.Tag, feature.Value, i)
+}
+
 func (p *String) MarshalJSON() ([]byte, error) {
 	marshaller := &StringFormly{p}
 	return marshaller.MarshalJSON()
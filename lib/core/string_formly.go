@@ -10,29 +10,34 @@ type StringFormly struct {
 
 func (feature *StringFormly) MarshalJSON() ([]byte, error) {
 	type jsonFeature struct {
-		Type     string `json:"type"`
-		Title    string `json:"title"`
-		Value    string `json:"value,omitempty"`
-		Disabled bool   `json:"disabled,omitempty"`
-		Default  string `json:"default,omitempty"`
-		Widget   struct {
+		Type      string `json:"type"`
+		Title     string `json:"title"`
+		Value     string `json:"value,omitempty"`
+		Disabled  bool   `json:"disabled,omitempty"`
+		Default   string `json:"default,omitempty"`
+		MinLength *int   `json:"minLength,omitempty"`
+		MaxLength *int   `json:"maxLength,omitempty"`
+		Pattern   string `json:"pattern,omitempty"`
+		Widget    struct {
 			FormlyConfig map[string]any `json:"formlyConfig,omitempty"`
 		} `json:"widget,omitempty"`
 	}
 	foo := jsonFeature{
-		Type:     feature.Type,
-		Title:    feature.Title,
-		Value:    feature.Value,
-		Disabled: feature.Disabled,
-		Default:  feature.Default,
+		Type:      feature.Type,
+		Title:     feature.Title,
+		Value:     feature.Value,
+		Disabled:  feature.Disabled,
+		Default:   feature.Default,
+		MinLength: feature.MinLength,
+		MaxLength: feature.MaxLength,
+		Pattern:   feature.Pattern,
 	}
 
+	props := make(map[string]any)
 	if feature.InfoUrl != "" {
-		foo.Widget.FormlyConfig = make(map[string]any)
-		foo.Widget.FormlyConfig["props"] = map[string]any{
-			"info_url": feature.InfoUrl,
-		}
+		props["info_url"] = feature.InfoUrl
 	}
+	foo.Widget.FormlyConfig = feature.Rules.formlyConfig(props)
 
 	valueBytes, err := json.Marshal(foo)
 	if err != nil {
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// UNDO_LIMIT is the number of changes that can be undone.
+const UNDO_LIMIT = 100
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// TagChange is the change of the value of a tag.
+type TagChange struct {
+	Tag string `json:"tag"`
+	Old any    `json:"old"`
+	New any    `json:"new"`
+}
+
+// Change is an answer given with SetFeature, or the last of those given in
+// a Batch, with the other tags it changed through the conditions.
+type Change struct {
+	TagChange
+	Derived []TagChange `json:"derived"`
+
+	before, after projectState
+}
+
+// projectState is what Undo and Redo restore.
+type projectState struct {
+	values any
+	tags   map[string]any
+}
+
+func (p *Project) state() projectState {
+	return projectState{values: p.GetValue(), tags: maps.Clone(p.Tags)}
+}
+
+// Batch runs fn as a single change, so that the answers it gives are undone
+// at once.
+func (p *Project) Batch(fn func() error) error {
+	if p.loading || p.change != nil {
+		return fn()
+	}
+	p.change = &Change{before: p.state()}
+	err := fn()
+	c := p.change
+	p.change = nil
+	// a batch failing halfway can be undone as well
+	if c.Tag == "" {
+		return err
+	}
+	c.after = p.state()
+	c.Derived = derivedChanges(c.Tag, c.before.tags, c.after.tags)
+	p.undo = append(p.undo, *c)
+	if len(p.undo) > UNDO_LIMIT {
+		p.undo = slices.Delete(p.undo, 0, len(p.undo)-UNDO_LIMIT)
+	}
+	p.redo = nil
+	return err
+}
+
+// changed notes the answer given to the change being made.
+func (p *Project) changed(tag string, old, value any) {
+	if p.change == nil || reflect.DeepEqual(old, value) {
+		return
+	}
+	p.change.TagChange = TagChange{Tag: tag, Old: old, New: value}
+}
+
+// derivedChanges returns the tags changed besides the answered one, by tag.
+func derivedChanges(answered string, before, after map[string]any) []TagChange {
+	tags := slices.Collect(maps.Keys(after))
+	for tag := range before {
+		if _, ok := after[tag]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	changes := make([]TagChange, 0)
+	for _, tag := range tags {
+		if tag == answered || strings.HasPrefix(answered, tag+".") {
+			continue
+		}
+		if !reflect.DeepEqual(before[tag], after[tag]) {
+			changes = append(changes, TagChange{Tag: tag, Old: before[tag], New: after[tag]})
+		}
+	}
+	return changes
+}
+
+func (p *Project) CanUndo() bool {
+	return len(p.undo) > 0
+}
+
+func (p *Project) CanRedo() bool {
+	return len(p.redo) > 0
+}
+
+// Changes returns the changes that can be undone, the last one first.
+func (p *Project) Changes() []Change {
+	changes := slices.Clone(p.undo)
+	slices.Reverse(changes)
+	return changes
+}
+
+// Undo restores the answers given before the last change, and returns it.
+func (p *Project) Undo() (*Change, error) {
+	if len(p.undo) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	c := p.undo[len(p.undo)-1]
+	if err := p.restore(c.before); err != nil {
+		return nil, err
+	}
+	p.undo = p.undo[:len(p.undo)-1]
+	p.redo = append(p.redo, c)
+	return &c, nil
+}
+
+// Redo gives again the answers of the last change undone, and returns it.
+func (p *Project) Redo() (*Change, error) {
+	if len(p.redo) == 0 {
+		return nil, ErrNothingToRedo
+	}
+	c := p.redo[len(p.redo)-1]
+	if err := p.restore(c.after); err != nil {
+		return nil, err
+	}
+	p.redo = p.redo[:len(p.redo)-1]
+	p.undo = append(p.undo, c)
+	return &c, nil
+}
+
+// restore sets the answers of a state, recording them in the audit log.
+func (p *Project) restore(s projectState) error {
+	before := p.answers()
+	if err := p.SetValue(s.values); err != nil {
+		return err
+	}
+	if _, err := p.Validate(""); err != nil {
+		return err
+	}
+	p.recordAnswers(before)
+	p.SetDirty(true)
+	return nil
+}
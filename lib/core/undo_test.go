@@ -0,0 +1,91 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestUndoRedo(t *testing.T) {
+	p := newTestProject(t, boundedDecimalConfig, nil)
+	mustSet(t, p, "power", json.Number("60"))
+	mustSet(t, p, "power", json.Number("10"))
+
+	c, err := p.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Tag != "power" || fmt.Sprint(p.Tags["power"]) != "60" || p.Tags["big"] != true {
+		t.Errorf("undo: got %v, big %v", p.Tags["power"], p.Tags["big"])
+	}
+	if len(c.Derived) != 1 || c.Derived[0].Tag != "big" {
+		t.Errorf("undo: got derived %v, want big", c.Derived)
+	}
+	if _, err := p.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(p.Tags["power"]) != "10" || p.Tags["big"] == true {
+		t.Errorf("redo: got %v, big %v", p.Tags["power"], p.Tags["big"])
+	}
+	if _, err := p.Redo(); err != ErrNothingToRedo {
+		t.Errorf("redo past the last change: %v", err)
+	}
+}
+
+func TestProjectSetValueReportsErrors(t *testing.T) {
+	p := newTestProject(t, boundedDecimalConfig, nil)
+	if err := p.SetValue(map[string]any{"form": map[string]any{"power": json.Number("1000")}}); err == nil {
+		t.Errorf("a value above the maximum is accepted")
+	}
+}
+
+func TestArrayItemChanges(t *testing.T) {
+	p := newTestProject(t, `{"name": "T", "features": [{"type": "array", "tag": "parcels", "title": "Parcels", "items": {"title": "Parcel", "properties": {
+		"area": {"type": "string", "tag": "area", "title": "Area"}}}}]}`, nil)
+	count := func() any {
+		return p.Tags["parcels"].(map[string]any)[ARRAY_COUNT]
+	}
+
+	for range 2 {
+		if err := p.AddArrayItem("parcels"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustSet(t, p, "parcels.1.area", "north")
+	if err := p.MoveArrayItem("parcels", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.answer("parcels.0.area"); got != "north" {
+		t.Errorf("got the first area %v, want north", got)
+	}
+	if err := p.RemoveArrayItem("parcels", 1); err != nil {
+		t.Fatal(err)
+	}
+	if count() != int64(1) {
+		t.Errorf("got %v items, want 1", count())
+	}
+
+	tags := make([]string, 0)
+	for _, e := range p.AuditLog() {
+		tags = append(tags, e.Tag)
+	}
+	if want := []string{"parcels", "parcels", "parcels.1.area", "parcels", "parcels"}; fmt.Sprint(tags) != fmt.Sprint(want) {
+		t.Errorf("got the audit entries %v, want %v", tags, want)
+	}
+
+	if _, err := p.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if count() != int64(2) || p.answer("parcels.0.area") != "north" {
+		t.Errorf("the removal is not undone: %v items", count())
+	}
+	if _, err := p.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if p.answer("parcels.1.area") != "north" {
+		t.Errorf("the move is not undone")
+	}
+	if err := p.RemoveArrayItem("missing", 0); err == nil {
+		t.Errorf("an unknown array is changed")
+	}
+}
@@ -3,11 +3,13 @@ module terra9.it/checkmate
 go 1.24.5
 
 require (
-	github.com/mitchellh/pointerstructure v1.2.1 // indirect
+	github.com/mitchellh/pointerstructure v1.2.1
 	github.com/stretchr/testify v1.8.4 // indirect
 )
 
 require (
-	github.com/gterranova/go-bexpr v0.1.12-beta
+	github.com/gterranova/go-bexpr v0.1.13
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	github.com/shopspring/decimal v1.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
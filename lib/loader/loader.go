@@ -8,13 +8,20 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 )
 
+// ResourceLoader reads the files of a package from its archive or from
+// disk. The files are cached in Data, guarded so that a loader can be
+// shared by concurrent renders.
 type ResourceLoader struct {
 	ResourceName string
 	BasePath     string
 	Data         map[string][]byte
+
+	mu sync.RWMutex
 }
 
 func (r *ResourceLoader) Name() string {
@@ -32,17 +39,50 @@ func (r *ResourceLoader) Load(filename string) ([]byte, bool) {
 
 func (r *ResourceLoader) Get(filename string) ([]byte, bool) {
 	path := path.Join(r.BasePath, r.ResourceName, filename)
-	if content, ok := r.Data[path]; ok {
+	r.mu.RLock()
+	content, ok := r.Data[path]
+	r.mu.RUnlock()
+	if ok {
 		return content, ok
 	}
 
 	if content, ok := r.Load(path); ok {
+		r.mu.Lock()
 		r.Data[path] = content
+		r.mu.Unlock()
 		return content, true
 	}
 	return nil, false
 }
 
+// List returns the names of the package files found in dir, relative to
+// the package root, whether they come from the archive or from disk.
+func (r *ResourceLoader) List(dir string) []string {
+	root := path.Join(r.BasePath, r.ResourceName)
+	prefix := path.Join(root, dir) + "/"
+	found := make(map[string]bool)
+	r.mu.RLock()
+	for k := range r.Data {
+		if name, ok := strings.CutPrefix(k, prefix); ok && !strings.Contains(name, "/") {
+			found[path.Join(dir, name)] = true
+		}
+	}
+	r.mu.RUnlock()
+	if entries, err := os.ReadDir(prefix); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() {
+				found[path.Join(dir, e.Name())] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func addFile(zipWriter *zip.Writer, filename string, src io.Reader) error {
 	w1, err := zipWriter.Create(filename)
 	if err != nil {
@@ -56,7 +96,9 @@ func addFile(zipWriter *zip.Writer, filename string, src io.Reader) error {
 
 func (r *ResourceLoader) Set(filename string, data []byte) error {
 	path := path.Join(r.BasePath, r.ResourceName, filename)
+	r.mu.Lock()
 	r.Data[filename] = data
+	r.mu.Unlock()
 	os.WriteFile(path, data, 0644)
 	return nil
 }
@@ -86,6 +128,9 @@ func (r *ResourceLoader) SaveAs(outputFile string) error {
 	zipWriter := zip.NewWriter(archive)
 	defer zipWriter.Close()
 
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	// Iterate through the files in the zip archive
 	for k, v := range r.Data {
 		zippedFilename, _ := strings.CutPrefix(k, path)
@@ -130,7 +175,9 @@ func (r *ResourceLoader) Unpack(b *[]byte) error {
 			return err
 		}
 		path := path.Join(r.BasePath, r.ResourceName, f.Name)
+		r.mu.Lock()
 		r.Data[path] = b
+		r.mu.Unlock()
 		// Print the file name and contents
 		//fmt.Printf("File Name: %s\n", path)
 		//fmt.Printf("%s\n", string(b))
@@ -0,0 +1,41 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentGet(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	const files = 50
+	for i := range files {
+		if err := os.WriteFile(filepath.Join(dir, "pkg", fmt.Sprintf("%d.txt", i)), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := NewEmptyLoader(filepath.Join(dir, "pkg"))
+
+	// the files read are cached while other goroutines read the cache
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range files {
+				if _, ok := r.Get(fmt.Sprintf("%d.txt", i)); !ok {
+					t.Errorf("%d.txt not found", i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := len(r.List(".")); got != files {
+		t.Errorf("listed %d files, want %d", got, files)
+	}
+}
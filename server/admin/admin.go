@@ -0,0 +1,122 @@
+// Package admin implements the administration commands of the server, run
+// as `server [-c config] user <command>` against the server database.
+package admin
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"terra9.it/checkmate/server/models"
+	"terra9.it/checkmate/server/repository"
+)
+
+const usage = `usage: server [-c config] user <command> [arguments]
+
+commands:
+  add [-role r] [-password p] <email>   create an account, a viewer by default
+  list                                  list the accounts
+  passwd [-password p] <email>          set the password of an account
+  role <email> <role>                   set the role of an account
+  unlock <email>                        unlock an account locked by failed logins
+  reset <email>                         print a password reset token
+  delete <email>                        delete an account
+
+The roles are viewer, editor, author and admin.
+
+The password is read from the standard input when -password is not given.`
+
+// Run runs an administration command.
+func Run(args []string) error {
+	if len(args) < 2 || args[0] != "user" {
+		return fmt.Errorf("%s", usage)
+	}
+	command, args := args[1], args[2:]
+	switch command {
+	case "add", "list", "passwd", "role", "unlock", "reset", "delete":
+	default:
+		return fmt.Errorf("unknown command %s\n%s", command, usage)
+	}
+	users := repository.NewUserStore()
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	role := flags.String("role", models.ROLE_VIEWER, "role of the account")
+	password := flags.String("password", "", "password of the account")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	if command == "list" {
+		list, err := users.List()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tEMAIL\tROLE\tLOCKED\tCREATED")
+		for _, u := range list {
+			locked := ""
+			if time.Now().Before(u.LockedUntil) {
+				locked = u.LockedUntil.Local().Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", u.ID, u.Email, u.Role, locked, u.Created.Local().Format(time.DateTime))
+		}
+		return w.Flush()
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("%s", usage)
+	}
+	email := args[0]
+	if command == "add" {
+		if *password == "" {
+			*password = readPassword(os.Stdin)
+		}
+		user, err := users.Create(email, *password, *role)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("user %s created with id %d\n", user.Email, user.ID)
+		return nil
+	}
+
+	user, err := users.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+	switch command {
+	case "passwd":
+		if *password == "" {
+			*password = readPassword(os.Stdin)
+		}
+		return users.SetPassword(user.ID, *password)
+	case "role":
+		if len(args) < 2 {
+			return fmt.Errorf("%s", usage)
+		}
+		return users.SetRole(user.ID, args[1])
+	case "unlock":
+		return users.Unlock(user.ID)
+	case "reset":
+		token, err := users.NewResetToken(user.Email)
+		if err != nil {
+			return err
+		}
+		fmt.Println(token)
+		return nil
+	case "delete":
+		return users.Delete(user.ID)
+	}
+	return nil
+}
+
+func readPassword(r io.Reader) string {
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, _ := bufio.NewReader(r).ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"terra9.it/checkmate/server/models"
+)
+
+// The access to a folder, and to its subfolders, is restricted by the
+// params of its _meta.yaml:
+//
+//	read_role: viewer      role needed to see the pages
+//	write_role: editor     role needed by the PUT, POST, PATCH and DELETE
+//	                       requests, besides the read role
+//	render_role: author    role needed by the modes telling what the answers
+//	                       lead to, see renderModes, besides the read role
+//	hide_restricted: true  leave the pages out of the breadcrumbs of the
+//	                       users not allowed to see them
+//
+// The roles are models.Roles; pages without roles are public.
+
+var writeMethods = []string{fiber.MethodPut, fiber.MethodPost, fiber.MethodPatch, fiber.MethodDelete}
+
+// renderModes need the render role: the reports, and the derived tags and
+// expressions shown by :diff, :sensitivity and :explain. The :history and
+// :projects modes only show the answers of the user, and need the read role.
+var renderModes = []string{"render", "download", "diff", "sensitivity", "explain"}
+
+// RoleFromContext returns the role of the logged in user, set by the
+// session middleware, or an empty string.
+func RoleFromContext(ctx *fiber.Ctx) string {
+	role, _ := ctx.Locals("role").(string)
+	return role
+}
+
+func (config *HandlerConfig) param(name string) string {
+	value, _ := config.Params[name].(string)
+	return value
+}
+
+// RequiredRole returns the role needed by a request with the given method,
+// an empty string when anyone is allowed.
+func (config *HandlerConfig) RequiredRole(method string) string {
+	required := config.param("read_role")
+	if slices.Contains(writeMethods, method) {
+		required = models.HigherRole(required, config.param("write_role"))
+	}
+	if slices.Contains(renderModes, config.param("mode")) {
+		required = models.HigherRole(required, config.param("render_role"))
+	}
+	return required
+}
+
+// Authorize returns 401 for the anonymous users and 403 for the others when
+// the role of the user does not allow the request.
+func (config *HandlerConfig) Authorize(method string) error {
+	if models.RoleAllows(config.Role, config.RequiredRole(method)) {
+		return nil
+	}
+	if config.Role == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "login required")
+	}
+	return fiber.NewError(fiber.StatusForbidden, "forbidden")
+}
+
+// Hidden reports whether the page is left out of the breadcrumbs of the
+// user.
+func (config *HandlerConfig) Hidden() bool {
+	hide, _ := config.Params["hide_restricted"].(bool)
+	return hide && !models.RoleAllows(config.Role, config.param("read_role"))
+}
+
+// ParentHandler returns the handler of a parent path, for the user of the
+// config.
+func ParentHandler(config *HandlerConfig, parent string) (Handler, error) {
+	parentConfig, err := ConfigForPath(config.Host, parent)
+	if err != nil {
+		return nil, err
+	}
+	parentConfig.Role = config.Role
+	return HandlerForConfig(parentConfig)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"terra9.it/checkmate/server/models"
+)
+
+func TestRequiredRole(t *testing.T) {
+	params := func(mode string) map[string]any {
+		return map[string]any{
+			"read_role":   models.ROLE_VIEWER,
+			"write_role":  models.ROLE_EDITOR,
+			"render_role": models.ROLE_AUTHOR,
+			"mode":        mode,
+		}
+	}
+	tests := []struct {
+		method, mode, want string
+	}{
+		{fiber.MethodGet, "", models.ROLE_VIEWER},
+		{fiber.MethodPost, "", models.ROLE_EDITOR},
+		{fiber.MethodGet, "history", models.ROLE_VIEWER},
+		{fiber.MethodGet, "projects", models.ROLE_VIEWER},
+		{fiber.MethodGet, "render", models.ROLE_AUTHOR},
+		{fiber.MethodGet, "download", models.ROLE_AUTHOR},
+		{fiber.MethodPost, "diff", models.ROLE_AUTHOR},
+		{fiber.MethodGet, "sensitivity", models.ROLE_AUTHOR},
+		{fiber.MethodGet, "explain", models.ROLE_AUTHOR},
+	}
+	for _, tt := range tests {
+		config := &HandlerConfig{Params: params(tt.mode)}
+		if got := config.RequiredRole(tt.method); got != tt.want {
+			t.Errorf("%s :%s needs %q, want %q", tt.method, tt.mode, got, tt.want)
+		}
+	}
+}
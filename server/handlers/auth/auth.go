@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -44,6 +46,13 @@ func (t *AuthHandler) Paginate() (*handlers.Pagination, error) {
 
 // Call implements handlers.Handler.
 func (a *AuthHandler) Call(ctx *fiber.Ctx) error {
+	if strings.HasSuffix(a.config.Path, "/oidc/login") {
+		return a.OidcLogin(ctx)
+	}
+	if strings.HasSuffix(a.config.Path, "/oidc/callback") {
+		return a.OidcCallback(ctx)
+	}
+
 	claims, err := ClaimsFromContext(ctx)
 	if err != nil {
 		// not logged in
@@ -51,12 +60,24 @@ func (a *AuthHandler) Call(ctx *fiber.Ctx) error {
 			return a.Login(ctx)
 		}
 
-		if strings.HasSuffix(a.config.Path, "/settings") {
+		if strings.HasSuffix(a.config.Path, "/settings") || strings.HasSuffix(a.config.Path, "/users") {
 			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": err.Error(),
 			})
 		}
 	}
+	if strings.HasSuffix(a.config.Path, "/register") {
+		return a.Register(ctx)
+	}
+
+	if strings.HasSuffix(a.config.Path, "/reset") {
+		return a.Reset(ctx)
+	}
+
+	if strings.HasSuffix(a.config.Path, "/users") {
+		return a.Users(ctx)
+	}
+
 	if strings.HasSuffix(a.config.Path, "/logout") {
 		return a.Logout(ctx)
 	}
@@ -69,10 +90,14 @@ func (a *AuthHandler) Call(ctx *fiber.Ctx) error {
 		return ctx.JSON(fiber.Map{
 			"ID":    claims["ID"],
 			"email": claims["email"],
+			"role":  handlers.RoleFromContext(ctx),
 		})
 	}
 
 	if strings.HasSuffix(a.config.Path, "/settings") {
+		if ctx.Method() == fiber.MethodPost {
+			return a.ChangePassword(ctx)
+		}
 		return a.SettingsPage(ctx)
 	}
 
@@ -109,27 +134,12 @@ func (a *AuthHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 	// Find the user by credentials
-	user, err := repository.FindByCredentials(loginRequest.Changes.Email, loginRequest.Changes.Password)
+	user, err := repository.Users.FindByCredentials(loginRequest.Changes.Email, loginRequest.Changes.Password)
 
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return userError(c, err)
 	}
-	day := time.Hour * 24
-	// Create the JWT claims, which includes the user ID and expiry time
-	claims := jwt.MapClaims{
-		"ID":         user.ID,
-		"email":      user.Email,
-		"session_id": user.SessionID,
-		"exp":        time.Now().Add(day * 1).Unix(),
-	}
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	// Generate encoded token and send it as response.
-	key := viper.GetString("AUTH_SECRET")
-
-	t, err := token.SignedString([]byte(key))
+	t, err := signToken(user)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -150,6 +160,153 @@ func (a *AuthHandler) Login(c *fiber.Ctx) error {
 	//return a.SettingsPage(c)
 }
 
+// Register route, creating an account with the AUTH_REGISTRATION_ROLE when
+// AUTH_REGISTRATION is set
+func (a *AuthHandler) Register(c *fiber.Ctx) error {
+	if c.Method() != fiber.MethodPost {
+		return fiber.ErrMethodNotAllowed
+	}
+	if !viper.GetBool("AUTH_REGISTRATION") {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "registration is disabled",
+		})
+	}
+	request := new(models.FormResponse[models.LoginRequest])
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	user, err := repository.Users.Create(request.Changes.Email, request.Changes.Password, viper.GetString("AUTH_REGISTRATION_ROLE"))
+	if err != nil {
+		return userError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"user": user,
+	})
+}
+
+// ChangePassword route, for the logged in user knowing the current password
+func (a *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	request := new(models.FormResponse[models.PasswordRequest])
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	id, err := UserIDFromContext(c)
+	if err != nil {
+		return userError(c, err)
+	}
+	if err := repository.Users.ChangePassword(id, request.Changes.Password, request.Changes.NewPassword); err != nil {
+		return userError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"status": "ok",
+	})
+}
+
+// Reset route. Given an email, it logs the request for the administrators,
+// who make the reset token with the "user reset" command and hand it over
+// as the server has no mailer; the response does not tell whether the
+// email exists. Given a token and a password, it sets the password.
+func (a *AuthHandler) Reset(c *fiber.Ctx) error {
+	if c.Method() != fiber.MethodPost {
+		return fiber.ErrMethodNotAllowed
+	}
+	request := new(models.FormResponse[models.ResetRequest])
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if request.Changes.Token != "" {
+		if err := repository.Users.ResetPassword(request.Changes.Token, request.Changes.Password); err != nil {
+			return userError(c, err)
+		}
+	} else if _, err := repository.Users.GetByEmail(request.Changes.Email); err == nil {
+		log.Printf("password reset requested for %s", request.Changes.Email)
+	} else if !errors.Is(err, repository.ErrUserNotFound) {
+		return userError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"status": "ok",
+	})
+}
+
+// Users route, for the administrators: GET lists the accounts, POST creates
+// one and DELETE deletes the one of the id query param.
+func (a *AuthHandler) Users(c *fiber.Ctx) error {
+	id, err := UserIDFromContext(c)
+	if err != nil {
+		return userError(c, err)
+	}
+	// the token may predate a change of role
+	if admin, err := repository.Users.Get(id); err != nil || admin.Role != models.ROLE_ADMIN {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "forbidden",
+		})
+	}
+
+	switch c.Method() {
+	case fiber.MethodGet:
+		users, err := repository.Users.List()
+		if err != nil {
+			return userError(c, err)
+		}
+		return c.JSON(fiber.Map{
+			"users": users,
+		})
+
+	case fiber.MethodPost:
+		request := new(models.FormResponse[models.UserRequest])
+		if err := c.BodyParser(request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		role := request.Changes.Role
+		if role == "" {
+			role = models.ROLE_VIEWER
+		}
+		user, err := repository.Users.Create(request.Changes.Email, request.Changes.Password, role)
+		if err != nil {
+			return userError(c, err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"user": user,
+		})
+
+	case fiber.MethodDelete:
+		if err := repository.Users.Delete(c.QueryInt("id")); err != nil {
+			return userError(c, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+	return fiber.ErrMethodNotAllowed
+}
+
+// userError sends the errors of the user store with their status.
+func userError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, repository.ErrInvalidCredentials):
+		status = fiber.StatusUnauthorized
+	case errors.Is(err, repository.ErrAccountLocked):
+		status = fiber.StatusTooManyRequests
+	case errors.Is(err, repository.ErrUserExists):
+		status = fiber.StatusConflict
+	case errors.Is(err, repository.ErrUserNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, repository.ErrInvalidEmail), errors.Is(err, repository.ErrWeakPassword),
+		errors.Is(err, repository.ErrInvalidResetToken), errors.Is(err, repository.ErrInvalidRole):
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
 func (a *AuthHandler) Logout(c *fiber.Ctx) error {
 	s := c.Locals("session")
 	if s != nil {
@@ -171,11 +328,23 @@ func (a *AuthHandler) Token(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	// the role may have changed since the login
+	id, err := UserIDFromContext(c)
+	if err != nil {
+		return err
+	}
+	user, err := repository.Users.Get(id)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	//day := time.Hour * 24
 	// Create the JWT claims, which includes the user ID and expiry time
 	newclaims := jwt.MapClaims{
 		"ID":         claims["ID"],
 		"email":      claims["email"],
+		"role":       user.Role,
 		"session_id": claims["session_id"],
 		//"exp":        time.Now().Add(day * 1).Unix(),
 		"exp": time.Now().Add(time.Second * 60).Unix(),
@@ -197,6 +366,25 @@ func (a *AuthHandler) Token(c *fiber.Ctx) error {
 	})
 }
 
+// signToken returns the JWT of a logged in user, the same for local and
+// OIDC logins.
+func signToken(user *models.User) (string, error) {
+	day := time.Hour * 24
+	// Create the JWT claims, which includes the user ID and expiry time
+	claims := jwt.MapClaims{
+		"ID":         user.ID,
+		"email":      user.Email,
+		"role":       user.Role,
+		"session_id": user.SessionID,
+		"exp":        time.Now().Add(day * 1).Unix(),
+	}
+	// Create token
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Generate encoded token
+	key := viper.GetString("AUTH_SECRET")
+	return token.SignedString([]byte(key))
+}
+
 func ClaimsFromContext(c *fiber.Ctx) (jwt.MapClaims, error) {
 	u := c.Locals("user")
 	if u == nil {
@@ -218,6 +406,20 @@ func ClaimsFromContext(c *fiber.Ctx) (jwt.MapClaims, error) {
 	return claims, nil
 }
 
+// UserIDFromContext returns the id of the logged in user.
+func UserIDFromContext(c *fiber.Ctx) (int, error) {
+	claims, err := ClaimsFromContext(c)
+	if err != nil {
+		return 0, err
+	}
+	// numbers of the json claims are decoded as float64
+	id, ok := claims["ID"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("invalid token claims")
+	}
+	return int(id), nil
+}
+
 func init() {
 	handlers.Manager.AddHandler("auth", func(config *handlers.HandlerConfig) handlers.Handler {
 		return &AuthHandler{
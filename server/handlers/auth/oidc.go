@@ -0,0 +1,435 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MicahParks/keyfunc/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+
+	"terra9.it/checkmate/server/models"
+	"terra9.it/checkmate/server/repository"
+)
+
+// OpenID Connect login, with the authorization code flow and PKCE, at
+// auth/oidc/login and auth/oidc/callback. The provider is set in the
+// environment like the hosts:
+//
+//	OIDC_ISSUER         url of the provider, serving its discovery document at
+//	                    /.well-known/openid-configuration; OIDC is off when unset
+//	OIDC_CLIENT_ID      the client registered with the provider
+//	OIDC_CLIENT_SECRET
+//	OIDC_REDIRECT_URL   url of the callback, e.g.
+//	                    https://example.com/api/v1/auth/oidc/callback
+//	OIDC_SCOPES         "openid email profile" by default
+//	OIDC_ROLE_CLAIM     claim with the role of the user, a string or a list of
+//	                    them of which the highest role is taken; roles are
+//	                    managed locally when unset
+//	OIDC_CREATE_USERS   whether unknown emails get an account, true by default
+//	OIDC_DEFAULT_ROLE   role of those accounts, viewer by default
+//	OIDC_SUCCESS_URL    where the browser goes after the login, with the token
+//	                    in the #token fragment; the callback answers like the
+//	                    login route when unset
+//
+// The provider claims map to the local user with the same email, who gets
+// the same token as with a local login.
+
+const (
+	oidcCookie         = "checkmate_oidc"
+	oidcCookieDuration = 10 * time.Minute
+)
+
+var (
+	errOidcDisabled   = errors.New("oidc login is not configured")
+	errOidcState      = errors.New("invalid or expired oidc state")
+	errOidcNoEmail    = errors.New("the provider gave no email, check the scopes")
+	errOidcUnverified = errors.New("the email is not verified by the provider")
+	errOidcNoAccount  = errors.New("no account for this email")
+)
+
+var oidcClient = &http.Client{Timeout: 10 * time.Second}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type oidcProvider struct {
+	oidcDiscovery
+	jwks *keyfunc.JWKS
+}
+
+var (
+	provider   *oidcProvider
+	providerMu sync.Mutex
+)
+
+// oidcProviderFromConfig discovers the provider at the first login, and again
+// after a failure.
+func oidcProviderFromConfig() (*oidcProvider, error) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	if provider != nil {
+		return provider, nil
+	}
+	issuer := viper.GetString("OIDC_ISSUER")
+	if issuer == "" {
+		return nil, errOidcDisabled
+	}
+
+	resp, err := oidcClient.Get(strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc discovery: %s", resp.Status)
+	}
+	var d oidcDiscovery
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&d); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if d.Issuer != issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %s does not match %s", d.Issuer, issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksURI == "" {
+		return nil, fmt.Errorf("oidc discovery: missing endpoints")
+	}
+
+	jwks, err := keyfunc.Get(d.JwksURI, keyfunc.Options{
+		Client:            oidcClient,
+		RefreshInterval:   time.Hour,
+		RefreshRateLimit:  time.Minute,
+		RefreshUnknownKID: true,
+		RefreshErrorHandler: func(err error) {
+			log.Println("oidc jwks:", err)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+	provider = &oidcProvider{oidcDiscovery: d, jwks: jwks}
+	return provider, nil
+}
+
+// oidcState is kept in a signed cookie between the login and the callback.
+type oidcState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Expires  int64  `json:"expires"`
+}
+
+func oidcMac(payload string) []byte {
+	h := hmac.New(sha256.New, []byte(viper.GetString("AUTH_SECRET")))
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+func (s *oidcState) encode() (string, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(oidcMac(payload)), nil
+}
+
+func decodeOidcState(value string) (*oidcState, error) {
+	payload, mac, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, errOidcState
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(mac)
+	if err != nil || !hmac.Equal(sum, oidcMac(payload)) {
+		return nil, errOidcState
+	}
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errOidcState
+	}
+	var s oidcState
+	if err := json.Unmarshal(b, &s); err != nil || time.Now().Unix() > s.Expires {
+		return nil, errOidcState
+	}
+	return &s, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// oidcCookiePath restricts the state cookie to the callback.
+func oidcCookiePath() string {
+	if u, err := url.Parse(viper.GetString("OIDC_REDIRECT_URL")); err == nil && u.Path != "" {
+		return u.Path
+	}
+	return "/"
+}
+
+func oidcError(c *fiber.Ctx, status int, err error) error {
+	log.Println("oidc:", err)
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// OidcLogin route, redirecting to the provider
+func (a *AuthHandler) OidcLogin(c *fiber.Ctx) error {
+	p, err := oidcProviderFromConfig()
+	if errors.Is(err, errOidcDisabled) {
+		return oidcError(c, fiber.StatusNotFound, err)
+	} else if err != nil {
+		return oidcError(c, fiber.StatusBadGateway, err)
+	}
+
+	state := &oidcState{Expires: time.Now().Add(oidcCookieDuration).Unix()}
+	for _, s := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+		if *s, err = randomString(); err != nil {
+			return oidcError(c, fiber.StatusInternalServerError, err)
+		}
+	}
+	value, err := state.encode()
+	if err != nil {
+		return oidcError(c, fiber.StatusInternalServerError, err)
+	}
+	redirect := viper.GetString("OIDC_REDIRECT_URL")
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcCookie,
+		Value:    value,
+		Path:     oidcCookiePath(),
+		MaxAge:   int(oidcCookieDuration.Seconds()),
+		Secure:   strings.HasPrefix(redirect, "https:"),
+		HTTPOnly: true,
+		// sent back by the redirect of the provider
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {viper.GetString("OIDC_CLIENT_ID")},
+		"redirect_uri":          {redirect},
+		"scope":                 {viper.GetString("OIDC_SCOPES")},
+		"state":                 {state.State},
+		"nonce":                 {state.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return c.Redirect(p.AuthorizationEndpoint + sep + query.Encode())
+}
+
+// OidcCallback route, logging in the user the provider redirected back
+func (a *AuthHandler) OidcCallback(c *fiber.Ctx) error {
+	p, err := oidcProviderFromConfig()
+	if errors.Is(err, errOidcDisabled) {
+		return oidcError(c, fiber.StatusNotFound, err)
+	} else if err != nil {
+		return oidcError(c, fiber.StatusBadGateway, err)
+	}
+
+	state, err := decodeOidcState(c.Cookies(oidcCookie))
+	// each state is used once
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcCookie,
+		Path:     oidcCookiePath(),
+		Expires:  time.Unix(0, 0),
+		HTTPOnly: true,
+	})
+	if err != nil {
+		return oidcError(c, fiber.StatusBadRequest, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Query("state")), []byte(state.State)) != 1 {
+		return oidcError(c, fiber.StatusBadRequest, errOidcState)
+	}
+	if e := c.Query("error"); e != "" {
+		return oidcError(c, fiber.StatusUnauthorized, fmt.Errorf("%s: %s", e, c.Query("error_description")))
+	}
+	code := c.Query("code")
+	if code == "" {
+		return oidcError(c, fiber.StatusBadRequest, errors.New("missing code"))
+	}
+
+	idToken, err := p.exchange(c.UserContext(), code, state.Verifier)
+	if err != nil {
+		return oidcError(c, fiber.StatusUnauthorized, err)
+	}
+	claims, err := p.verify(idToken, state.Nonce)
+	if err != nil {
+		return oidcError(c, fiber.StatusUnauthorized, err)
+	}
+	user, err := oidcUser(claims)
+	switch {
+	case errors.Is(err, errOidcNoEmail), errors.Is(err, errOidcUnverified), errors.Is(err, errOidcNoAccount):
+		return oidcError(c, fiber.StatusForbidden, err)
+	case errors.Is(err, repository.ErrAccountLocked):
+		return oidcError(c, fiber.StatusTooManyRequests, err)
+	case err != nil:
+		return userError(c, err)
+	}
+
+	t, err := signToken(user)
+	if err != nil {
+		return oidcError(c, fiber.StatusInternalServerError, err)
+	}
+	if success := viper.GetString("OIDC_SUCCESS_URL"); success != "" {
+		return c.Redirect(success + "#token=" + url.QueryEscape(t))
+	}
+	c.Response().Header.Set("Authorization", t)
+	a.config.Params["user"] = user
+	// a browser following redirects cannot read the header
+	return c.JSON(fiber.Map{
+		"user":  user,
+		"token": t,
+	})
+}
+
+// exchange trades the authorization code for the id token.
+func (p *oidcProvider) exchange(ctx context.Context, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {viper.GetString("OIDC_REDIRECT_URL")},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(viper.GetString("OIDC_CLIENT_ID")), url.QueryEscape(viper.GetString("OIDC_CLIENT_SECRET")))
+
+	resp, err := oidcClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oidc token: %w", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("oidc token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if body.Error != "" {
+			return "", fmt.Errorf("oidc token: %s", strings.TrimSpace(body.Error+" "+body.ErrorDescription))
+		}
+		return "", fmt.Errorf("oidc token: %s", resp.Status)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("oidc token: no id_token in the response")
+	}
+	return body.IDToken, nil
+}
+
+// verify checks the signature and the claims of the id token.
+func (p *oidcProvider) verify(idToken, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, p.jwks.Keyfunc,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(p.Issuer),
+		jwt.WithAudience(viper.GetString("OIDC_CLIENT_ID")),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc id token: %w", err)
+	}
+	if n, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(n), []byte(nonce)) != 1 {
+		return nil, errors.New("oidc id token: invalid nonce")
+	}
+	return claims, nil
+}
+
+// oidcRole returns the highest role in the OIDC_ROLE_CLAIM, if any.
+func oidcRole(claims jwt.MapClaims) string {
+	name := viper.GetString("OIDC_ROLE_CLAIM")
+	if name == "" {
+		return ""
+	}
+	var values []string
+	switch v := claims[name].(type) {
+	case string:
+		values = strings.Fields(v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	role := ""
+	for _, value := range values {
+		if models.ValidRole(value) {
+			role = models.HigherRole(role, value)
+		}
+	}
+	return role
+}
+
+// oidcUser maps the claims of the provider to a local user, with a new
+// session id. The email must be verified by the provider, as it is what
+// links the login to an account, and the accounts locked by failed logins
+// stay locked.
+func oidcUser(claims jwt.MapClaims) (*models.User, error) {
+	email, _ := claims["email"].(string)
+	if email == "" {
+		return nil, errOidcNoEmail
+	}
+	if verified, _ := claims["email_verified"].(bool); !verified {
+		return nil, errOidcUnverified
+	}
+	role := oidcRole(claims)
+
+	user, err := repository.Users.GetByEmail(email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		if !viper.GetBool("OIDC_CREATE_USERS") {
+			return nil, errOidcNoAccount
+		}
+		if role == "" {
+			role = viper.GetString("OIDC_DEFAULT_ROLE")
+		}
+		user, err = repository.Users.CreateExternal(email, role)
+	} else if err == nil && time.Now().Before(user.LockedUntil) {
+		err = repository.ErrAccountLocked
+	} else if err == nil && role != "" && role != user.Role {
+		err = repository.Users.SetRole(user.ID, role)
+		user.Role = role
+	}
+	if err != nil {
+		return nil, err
+	}
+	user.SessionID = utils.UUIDv4()
+	return user, nil
+}
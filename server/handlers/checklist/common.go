@@ -6,12 +6,15 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"terra9.it/checkmate/core"
 	"terra9.it/checkmate/server/handlers"
+	"terra9.it/checkmate/server/handlers/auth"
 	"terra9.it/checkmate/server/models"
+	"terra9.it/checkmate/server/repository"
 )
 
 func doPaginate(config *handlers.HandlerConfig, project *core.Project) (*handlers.Pagination, error) {
@@ -128,7 +131,7 @@ func doBreadcrumbs(config *handlers.HandlerConfig, basePath string, project *cor
 	parts := make([]*handlers.PageItem, 0)
 
 	parent := filepath.Dir(basePath)
-	parentHandler, err := handlers.HandlerForPath(config.Host, parent)
+	parentHandler, err := handlers.ParentHandler(config, parent)
 	if err == nil && parentHandler != nil {
 		// If the parent handler has pagination, get the path parts
 		pagination, err := parentHandler.Paginate()
@@ -172,6 +175,8 @@ func doSendForm(ctx *fiber.Ctx, config *handlers.HandlerConfig, project *core.Pr
 	}
 	params["type"] = "form"
 	params["title"] = project.Name
+	params["lang"] = project.Language()
+	params["languages"] = project.Languages()
 
 	if c, ok := config.Params["class"]; ok {
 		params["class"] = c
@@ -230,6 +235,10 @@ func doSendForm(ctx *fiber.Ctx, config *handlers.HandlerConfig, project *core.Pr
 		params["model"] = project.GetValue()
 	}
 
+	if errs := project.Errors(); len(errs) > 0 {
+		params["errors"] = errs.ByTag()
+	}
+
 	if !singlePage {
 		if pagination, err := doPaginate(config, project); err != nil {
 			return err
@@ -251,17 +260,49 @@ func doChecklist(ctx *fiber.Ctx, config *handlers.HandlerConfig, project *core.P
 	sess, _ := handlers.SessionFromContext(ctx)
 	params := config.Params
 
+	// the lang param wins over the browser languages
+	lang := ctx.Query("lang")
+	if lang == "" {
+		lang = project.MatchLanguage(ctx.Get(fiber.HeaderAcceptLanguage))
+	}
+	if lang != "" {
+		if err := project.SetLanguage(lang); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
 	if f, ok := params["single_page"]; ok {
 		singlePage = f.(bool)
 	}
 
-	if sess != nil {
+	if params["mode"] == "projects" {
+		return doProjects(ctx, config, project)
+	}
+	if params["mode"] == "history" {
+		return doHistory(ctx, config)
+	}
+
+	// answers are kept in the saved project chosen, or in the session
+	saved, err := savedProject(ctx, config)
+	if err != nil {
+		return err
+	}
+	if saved != nil {
+		values := make(map[string]any)
+		if err := core.UnmarshalValues(saved.Values, &values); err != nil {
+			return err
+		}
+		if err := project.SetValue(values); err != nil {
+			return err
+		}
+		params["project"] = saved
+	} else if sess != nil {
 		data := sess.Get("project")
 		if data != nil {
 			//var export core.ProjectExport
 			//err := json.Unmarshal(data.([]byte), &export)
 			values := make(map[string]any)
-			err := json.Unmarshal(data.([]byte), &values)
+			err := core.UnmarshalValues(data.([]byte), &values)
 			if err != nil {
 				return err
 			}
@@ -280,12 +321,18 @@ func doChecklist(ctx *fiber.Ctx, config *handlers.HandlerConfig, project *core.P
 		}()
 	}
 
+	// the changes made by this request are the audit entries from here
+	audited := len(project.AuditLog())
+	if claims, err := auth.ClaimsFromContext(ctx); err == nil {
+		project.User, _ = claims["email"].(string)
+	}
+
 	if ctx.Method() == "PUT" {
 		var export models.FormResponse[map[string]any]
 		export.Changes = make(map[string]any)
 		export.Model = make(map[string]any)
 
-		if err := ctx.BodyParser(&export); err != nil {
+		if err := core.UnmarshalValues(ctx.Body(), &export); err != nil {
 			return fiber.NewError(500, err.Error())
 		}
 		var featureName string
@@ -326,10 +373,30 @@ func doChecklist(ctx *fiber.Ctx, config *handlers.HandlerConfig, project *core.P
 		}
 	}
 	//project.UpdateTags()
-	_, err := project.Validate("")
-	if err != nil {
+	if _, err := project.Validate(""); err != nil {
+		return err
+	}
+	if saved != nil && ctx.Method() == "PUT" {
+		buf, _ := json.Marshal(project.GetValue())
+		if err := repository.Projects.Save(saved.UserID, saved.ID, buf); err != nil {
+			return err
+		}
+	}
+	if err := auditChanges(ctx, config, saved, project.AuditLog()[audited:]); err != nil {
 		return err
 	}
+	if params["mode"] == "diff" {
+		return doDiff(ctx, config, project)
+	}
+	if params["mode"] == "sensitivity" {
+		return doSensitivity(ctx, project, ctx.Query("tag"))
+	}
+	if params["mode"] == "explain" {
+		return doExplain(ctx, project, ctx.Query("tag"))
+	}
+	if mode := params["mode"]; mode == "render" || mode == "download" {
+		return doRender(ctx, project, ctx.Query("template"), ctx.Query("format"), mode == "download")
+	}
 	if feedback, ok := params["feedback"]; ok {
 		output := project.Evaluate()
 		config.Params[feedback.(string)] = strings.TrimSpace(output)
@@ -365,6 +432,53 @@ func doChecklist(ctx *fiber.Ctx, config *handlers.HandlerConfig, project *core.P
 	return doSendForm(ctx, config, project)
 }
 
+// doRender sends the report rendered by a template for the answers of the
+// session, the first template of the language when name is empty. The
+// report is converted to format, md, html, docx or pdf, when given; html
+// reports are shown by the browser unless download is set.
+func doRender(ctx *fiber.Ctx, project *core.Project, name string, format string, download bool) error {
+	var t *core.TemplateDef
+	if name != "" {
+		t = project.GetTemplateDef(name)
+	} else if templates := project.Templates(); len(templates) > 0 {
+		t = templates[0]
+	}
+	if t == nil {
+		return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("template %s not found", name))
+	}
+
+	var report *core.Report
+	var err error
+	if format != "" {
+		if format == "markdown" {
+			format = "md"
+		}
+		if !slices.Contains(renderFormats, format) {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unknown format %s", format))
+		}
+		report, err = project.RenderFormat(ctx.UserContext(), t, format)
+	} else {
+		report, err = project.Render(ctx.UserContext(), t)
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+	if download || !strings.HasPrefix(report.ContentType, "text/html") {
+		ctx.Attachment(report.Filename)
+	} else {
+		ctx.Set(fiber.HeaderContentSecurityPolicy, reportContentSecurityPolicy)
+	}
+	ctx.Set(fiber.HeaderContentType, report.ContentType)
+	return ctx.Send(report.Data)
+}
+
+// reportContentSecurityPolicy is sent with the reports shown inline: the
+// html of a report has its own stylesheet and nothing else to load or run.
+const reportContentSecurityPolicy = "default-src 'none'; style-src 'unsafe-inline'; sandbox"
+
+// formats of the reports sent by doRender
+var renderFormats = []string{"md", "html", "docx", "pdf"}
+
 func ProjectBasePath(requestedPath string) (basePath string, err error) {
 	var info fs.FileInfo
 
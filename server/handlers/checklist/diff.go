@@ -0,0 +1,41 @@
+package checklist
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"terra9.it/checkmate/core"
+	"terra9.it/checkmate/server/handlers"
+	"terra9.it/checkmate/server/handlers/auth"
+	"terra9.it/checkmate/server/repository"
+)
+
+// doDiff sends the differences from the answers given, those of the session
+// or of the saved project chosen by the project query param, to the answers
+// of the saved project chosen by the with query param.
+func doDiff(ctx *fiber.Ctx, config *handlers.HandlerConfig, project *core.Project) error {
+	id := ctx.QueryInt("with")
+	if id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "with param is required")
+	}
+	uid, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	other, err := repository.Projects.Get(uid, int64(id))
+	if errors.Is(err, repository.ErrProjectNotFound) || (err == nil && other.Checklist != checklistPath(config)) {
+		return fiber.NewError(fiber.StatusNotFound, repository.ErrProjectNotFound.Error())
+	} else if err != nil {
+		return err
+	}
+
+	values := make(map[string]any)
+	if err := core.UnmarshalValues(other.Values, &values); err != nil {
+		return err
+	}
+	outcome, err := project.OutcomeOf(values)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+	return ctx.JSON(core.DiffOutcomes(project.Outcome(), outcome))
+}
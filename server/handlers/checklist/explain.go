@@ -0,0 +1,19 @@
+package checklist
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"terra9.it/checkmate/core"
+)
+
+// doExplain sends the explanation tree of the tag query param, with the
+// expressions that set its value and disabled state for the answers given.
+func doExplain(ctx *fiber.Ctx, project *core.Project, tag string) error {
+	if tag == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "tag param is required")
+	}
+	explanation, err := project.Explain(tag)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return ctx.JSON(explanation)
+}
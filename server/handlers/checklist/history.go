@@ -0,0 +1,67 @@
+package checklist
+
+import (
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+	"terra9.it/checkmate/core"
+	"terra9.it/checkmate/server/handlers"
+	"terra9.it/checkmate/server/handlers/auth"
+	"terra9.it/checkmate/server/models"
+	"terra9.it/checkmate/server/repository"
+)
+
+// doHistory sends the changes of the answers made by the user, in the saved
+// project chosen by the project query param or in the session, only those
+// of the tag query param when given.
+func doHistory(ctx *fiber.Ctx, config *handlers.HandlerConfig) error {
+	uid, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	saved, err := savedProject(ctx, config)
+	if err != nil {
+		return err
+	}
+	var projectID int64
+	if saved != nil {
+		projectID = saved.ID
+	}
+	records, err := repository.Audit.History(uid, checklistPath(config), projectID, ctx.Query("tag"))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(records)
+}
+
+// auditChanges stores the changes recorded by the project for the logged in
+// user; those of anonymous users are not kept.
+func auditChanges(ctx *fiber.Ctx, config *handlers.HandlerConfig, saved *models.SavedProject, entries []core.AuditEntry) error {
+	uid, err := auth.UserIDFromContext(ctx)
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+	var projectID int64
+	if saved != nil {
+		projectID = saved.ID
+	}
+	records := make([]*models.AuditRecord, 0, len(entries))
+	for _, e := range entries {
+		r := &models.AuditRecord{
+			UserID:    uid,
+			Email:     e.User,
+			Checklist: checklistPath(config),
+			ProjectID: projectID,
+			Tag:       e.Tag,
+			Time:      e.Time,
+		}
+		if r.Old, err = json.Marshal(e.Old); err != nil {
+			return err
+		}
+		if r.New, err = json.Marshal(e.New); err != nil {
+			return err
+		}
+		records = append(records, r)
+	}
+	return repository.Audit.Append(records)
+}
@@ -0,0 +1,106 @@
+package checklist
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"terra9.it/checkmate/core"
+	"terra9.it/checkmate/server/handlers"
+	"terra9.it/checkmate/server/handlers/auth"
+	"terra9.it/checkmate/server/models"
+	"terra9.it/checkmate/server/repository"
+)
+
+// checklistPath returns the path of the checklist in the document folder,
+// which identifies the checklist of the saved projects.
+func checklistPath(config *handlers.HandlerConfig) string {
+	return strings.TrimPrefix(strings.TrimPrefix(config.Path, config.Host.DocumentFolder), "/")
+}
+
+// savedProject returns the project of the user chosen by the project query
+// param, nil when the param is not given.
+func savedProject(ctx *fiber.Ctx, config *handlers.HandlerConfig) (*models.SavedProject, error) {
+	id := ctx.QueryInt("project")
+	if id <= 0 {
+		return nil, nil
+	}
+	uid, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	saved, err := repository.Projects.Get(uid, int64(id))
+	if errors.Is(err, repository.ErrProjectNotFound) || (err == nil && saved.Checklist != checklistPath(config)) {
+		return nil, fiber.NewError(fiber.StatusNotFound, repository.ErrProjectNotFound.Error())
+	}
+	return saved, err
+}
+
+// doProjects manages the projects of the user for the checklist: GET lists
+// them, POST creates one with the default answers, or a copy of the one
+// chosen by the project query param, PATCH renames the chosen one and
+// DELETE deletes it.
+func doProjects(ctx *fiber.Ctx, config *handlers.HandlerConfig, project *core.Project) error {
+	uid, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	saved, err := savedProject(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	var request models.ProjectRequest
+	if ctx.Method() == fiber.MethodPost || ctx.Method() == fiber.MethodPatch {
+		if err := ctx.BodyParser(&request); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		request.Name = strings.TrimSpace(request.Name)
+		if request.Name == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "project name is required")
+		}
+	}
+	if saved == nil && (ctx.Method() == fiber.MethodPatch || ctx.Method() == fiber.MethodDelete) {
+		return fiber.NewError(fiber.StatusBadRequest, "project param is required")
+	}
+
+	switch ctx.Method() {
+	case fiber.MethodGet:
+		projects, err := repository.Projects.List(uid, checklistPath(config))
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(projects)
+
+	case fiber.MethodPost:
+		var created *models.SavedProject
+		if saved != nil {
+			created, err = repository.Projects.Duplicate(uid, saved.ID, request.Name)
+		} else {
+			values, _ := json.Marshal(project.GetValue())
+			created, err = repository.Projects.Create(uid, checklistPath(config), request.Name, values)
+		}
+		if err != nil {
+			return err
+		}
+		return ctx.Status(fiber.StatusCreated).JSON(created)
+
+	case fiber.MethodPatch:
+		if err := repository.Projects.Rename(uid, saved.ID, request.Name); err != nil {
+			return err
+		}
+		renamed, err := repository.Projects.Get(uid, saved.ID)
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(renamed)
+
+	case fiber.MethodDelete:
+		if err := repository.Projects.Delete(uid, saved.ID); err != nil {
+			return err
+		}
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+	return fiber.ErrMethodNotAllowed
+}
@@ -0,0 +1,24 @@
+package checklist
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"terra9.it/checkmate/core"
+)
+
+// doSensitivity sends the ranges of values of the numeric tag query param
+// giving the same tags, the other answers being those given.
+func doSensitivity(ctx *fiber.Ctx, project *core.Project, tag string) error {
+	if tag == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "tag param is required")
+	}
+	if project.GetFeature(tag) == nil {
+		return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("feature %s not found", tag))
+	}
+	sensitivity, err := project.Sensitivity(tag)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+	return ctx.JSON(sensitivity)
+}
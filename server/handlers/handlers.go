@@ -25,6 +25,8 @@ type HandlerConfig struct {
 	Path        string
 	Data        []byte
 	Params      map[string]any
+	// role of the user of the request, empty for anonymous users
+	Role string
 }
 
 type Handler interface {
@@ -105,8 +107,17 @@ func FileHandler(config *HandlerConfig) (Handler, error) {
 }
 
 func HandlerForPath(host *Host, pathUrl string) (Handler, error) {
+	config, err := ConfigForPath(host, pathUrl)
+	if err != nil {
+		return nil, err
+	}
+	return HandlerForConfig(config)
+}
+
+// ConfigForPath returns the config of the handler of a path, with the params
+// of the _meta.yaml files of its folders.
+func ConfigForPath(host *Host, pathUrl string) (*HandlerConfig, error) {
 	var mode string
-	var err error
 
 	if host == nil {
 		return nil, fmt.Errorf("host is nil")
@@ -125,10 +136,13 @@ func HandlerForPath(host *Host, pathUrl string) (Handler, error) {
 	}
 
 	//fmt.Println("REQ PATH:", config.Path)
-	if err = MetaForPath(config, config.Path); err != nil {
+	if err := MetaForPath(config, config.Path); err != nil {
 		return nil, err
 	}
+	return config, nil
+}
 
+func HandlerForConfig(config *HandlerConfig) (Handler, error) {
 	if handlerName, ok := config.Params["handler"]; ok {
 		config.HandlerName = handlerName.(string)
 		delete(config.Params, "handler")
@@ -150,7 +164,16 @@ func Page(hosts map[string]*Host) fiber.Handler {
 		}
 		log.Println(ctx.Hostname(), ctx.Path(), host)
 
-		handler, err := HandlerForPath(host, ctx.Path())
+		config, err := ConfigForPath(host, ctx.Path())
+		if err != nil {
+			return PageNotFound(hosts)(ctx)
+		}
+		config.Role = RoleFromContext(ctx)
+		if err := config.Authorize(ctx.Method()); err != nil {
+			return err
+		}
+
+		handler, err := HandlerForConfig(config)
 		if err != nil {
 			return PageNotFound(hosts)(ctx)
 		}
@@ -166,7 +189,7 @@ func DefaultPagination(config *HandlerConfig) (*Pagination, error) {
 	parts := make([]*PageItem, 0)
 
 	parent := filepath.Dir(config.Path)
-	parentHandler, err := HandlerForPath(config.Host, parent)
+	parentHandler, err := ParentHandler(config, parent)
 	if err == nil && parentHandler != nil {
 		// If the parent handler has pagination, get the path parts
 		pagination, err := parentHandler.Paginate()
@@ -179,7 +202,7 @@ func DefaultPagination(config *HandlerConfig) (*Pagination, error) {
 	urlPath = strings.TrimPrefix(urlPath, "/")
 	urlPath = strings.TrimPrefix(urlPath, "\\")
 
-	if title, ok := config.Params["title"]; ok {
+	if title, ok := config.Params["title"]; ok && !config.Hidden() {
 		// If the title is already set in params, use it
 		parts = append(parts, &PageItem{
 			Href:  urlPath,
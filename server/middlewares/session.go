@@ -27,6 +27,13 @@ func NewSessionMiddleware(serverAddr string) fiber.Handler {
 			if session_id, ok := claims["session_id"].(string); ok {
 				c.Request().Header.Set("Session-Id", session_id)
 			}
+			// checked by the handlers against the roles of the pages; the
+			// token may predate a change of role
+			if id, err := auth.UserIDFromContext(c); err == nil {
+				if user, err := repository.Users.Get(id); err == nil {
+					c.Locals("role", user.Role)
+				}
+			}
 			//defer c.Response().Header.Del("Session-Id")
 		}
 
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -17,11 +22,70 @@ type LoginResponse struct {
 
 type UserResponse struct {
 	Email string `json:"email"`
+	Role  string `json:"role"`
 }
 
 type User struct {
-	ID        int    `json:"uid"`
-	Email     string `json:"email"`
-	Password  string `json:"-"`
-	SessionID string `json:"-"`
+	ID    int    `json:"uid"`
+	Email string `json:"email"`
+	// one of Roles
+	Role string `json:"role"`
+	// hash of the password, see repository.HashPassword
+	PasswordHash string    `json:"-"`
+	SessionID    string    `json:"-"`
+	Created      time.Time `json:"created"`
+	// failed logins since the last successful one, and the end of the
+	// lockout they caused
+	Failures    int       `json:"failures,omitempty"`
+	LockedUntil time.Time `json:"locked_until,omitzero"`
+}
+
+type PasswordRequest struct {
+	Password    string `json:"password"`
+	NewPassword string `json:"new_password"`
+}
+
+// ResetRequest asks for a reset token when only the email is given, and
+// sets the password with the token otherwise.
+type ResetRequest struct {
+	Email    string `json:"email"`
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type UserRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// SavedProject is a filled-in checklist saved by a user.
+type SavedProject struct {
+	ID     int64 `json:"id"`
+	UserID int   `json:"uid"`
+	// path of the checklist in the document folder
+	Checklist string `json:"checklist"`
+	Name      string `json:"name"`
+	// answers, as returned by Project.GetValue
+	Values  []byte    `json:"-"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type ProjectRequest struct {
+	Name string `json:"name"`
+}
+
+// AuditRecord is a change of an answer made through the server.
+type AuditRecord struct {
+	ID        int64  `json:"id"`
+	UserID    int    `json:"uid"`
+	Email     string `json:"user"`
+	Checklist string `json:"checklist"`
+	// saved project changed, 0 for the answers kept in the session
+	ProjectID int64           `json:"project"`
+	Tag       string          `json:"tag"`
+	Old       json.RawMessage `json:"old"`
+	New       json.RawMessage `json:"new"`
+	Time      time.Time       `json:"time"`
 }
@@ -0,0 +1,40 @@
+package models
+
+import "slices"
+
+// Roles of the users, from the least to the most trusted one. Each role is
+// granted what the previous ones are.
+const (
+	ROLE_VIEWER = "viewer"
+	ROLE_EDITOR = "editor"
+	ROLE_AUTHOR = "author"
+	ROLE_ADMIN  = "admin"
+)
+
+var Roles = []string{ROLE_VIEWER, ROLE_EDITOR, ROLE_AUTHOR, ROLE_ADMIN}
+
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+// RoleAllows reports whether a role is granted the required one. Anyone,
+// anonymous users having no role, is granted the empty role, and only the
+// admins an unknown one.
+func RoleAllows(role, required string) bool {
+	if required == "" {
+		return true
+	}
+	need := slices.Index(Roles, required)
+	if need < 0 {
+		need = slices.Index(Roles, ROLE_ADMIN)
+	}
+	return slices.Index(Roles, role) >= need
+}
+
+// HigherRole returns the most trusted of two required roles.
+func HigherRole(a, b string) string {
+	if RoleAllows(a, b) {
+		return a
+	}
+	return b
+}
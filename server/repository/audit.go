@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"terra9.it/checkmate/server/models"
+)
+
+// Audit is the store of the changes of the answers, set up by the server.
+var Audit *AuditStore
+
+// AuditStore keeps the changes of the answers in the audit table of the
+// server database. Records are only ever appended.
+type AuditStore struct {
+	db *sql.DB
+}
+
+func NewAuditStore() *AuditStore {
+	db := openDatabase()
+	query := `CREATE TABLE IF NOT EXISTS audit (
+		  id        INTEGER PRIMARY KEY AUTOINCREMENT,
+		  u         INTEGER NOT NULL,
+		  email     TEXT NOT NULL,
+		  checklist TEXT NOT NULL,
+		  project   INTEGER NOT NULL DEFAULT 0,
+		  tag       TEXT NOT NULL,
+		  old       BLOB,
+		  new       BLOB,
+		  time      BIGINT NOT NULL DEFAULT '0');
+		CREATE INDEX IF NOT EXISTS audit_tag ON audit (checklist, project, tag);`
+	if _, err := db.Exec(query); err != nil {
+		log.Fatal(err)
+	}
+	return &AuditStore{db: db}
+}
+
+// Append adds the records, all or none.
+func (s *AuditStore) Append(records []*models.AuditRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, r := range records {
+		if _, err := tx.Exec(`INSERT INTO audit (u, email, checklist, project, tag, old, new, time)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			r.UserID, r.Email, r.Checklist, r.ProjectID, r.Tag, []byte(r.Old), []byte(r.New), r.Time.Unix()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// History returns the changes made by a user to a checklist, in a saved
+// project or in the session when projectID is 0, the oldest first. Only the
+// changes of the tag are returned when one is given.
+func (s *AuditStore) History(userID int, checklist string, projectID int64, tag string) ([]*models.AuditRecord, error) {
+	rows, err := s.db.Query(`SELECT id, u, email, checklist, project, tag, old, new, time FROM audit
+		WHERE u = ? AND checklist = ? AND project = ? AND (? = '' OR tag = ?)
+		ORDER BY id`, userID, checklist, projectID, tag, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]*models.AuditRecord, 0)
+	for rows.Next() {
+		var r models.AuditRecord
+		var oldValue, newValue []byte
+		var t int64
+		if err := rows.Scan(&r.ID, &r.UserID, &r.Email, &r.Checklist, &r.ProjectID, &r.Tag, &oldValue, &newValue, &t); err != nil {
+			return nil, err
+		}
+		r.Old, r.New = oldValue, newValue
+		r.Time = time.Unix(t, 0).UTC()
+		records = append(records, &r)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// PASSWORD_MIN_LENGTH is the length of the shortest password accepted
+	PASSWORD_MIN_LENGTH = 8
+	// parameters of the argon2id password hashes, as recommended by OWASP:
+	// memory in KiB, iterations and threads
+	PASSWORD_MEMORY     = 19 * 1024
+	PASSWORD_ITERATIONS = 2
+	PASSWORD_THREADS    = 1
+	passwordScheme      = "argon2id"
+)
+
+var ErrWeakPassword = fmt.Errorf("password must be at least %d characters long", PASSWORD_MIN_LENGTH)
+
+// HashPassword returns the argon2id hash of a password in the form
+// argon2id$memory,iterations,threads$salt$key, salt and key being base64
+// encoded.
+func HashPassword(password string) (string, error) {
+	if len(password) < PASSWORD_MIN_LENGTH {
+		return "", ErrWeakPassword
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, PASSWORD_ITERATIONS, PASSWORD_MEMORY, PASSWORD_THREADS, 32)
+	return strings.Join([]string{
+		passwordScheme,
+		fmt.Sprintf("%d,%d,%d", PASSWORD_MEMORY, PASSWORD_ITERATIONS, PASSWORD_THREADS),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// CheckPassword reports whether the password matches a hash made by
+// HashPassword.
+func CheckPassword(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false, errors.New("unknown password hash")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false, err
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false, err
+	}
+
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[1], "%d,%d,%d", &memory, &iterations, &threads); err != nil {
+		return false, fmt.Errorf("invalid password hash parameters: %v", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(key, want) == 1, nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, passwordScheme+"$") {
+		t.Errorf("unexpected hash %s", hash)
+	}
+	if ok, err := CheckPassword(hash, "correct horse"); err != nil || !ok {
+		t.Errorf("the password does not match its hash: %v", err)
+	}
+	if ok, _ := CheckPassword(hash, "wrong horse"); ok {
+		t.Errorf("a wrong password matches")
+	}
+	if _, err := HashPassword("short"); err != ErrWeakPassword {
+		t.Errorf("a short password is hashed")
+	}
+}
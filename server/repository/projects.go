@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"terra9.it/checkmate/server/models"
+)
+
+var ErrProjectNotFound = errors.New("project not found")
+
+// Projects is the store of the saved projects, set up by the server.
+var Projects *ProjectStore
+
+// ProjectStore keeps the projects of the users in the projects table of the
+// server database.
+type ProjectStore struct {
+	db *sql.DB
+}
+
+func NewProjectStore() *ProjectStore {
+	db := openDatabase()
+	query := `CREATE TABLE IF NOT EXISTS projects (
+		  id        INTEGER PRIMARY KEY AUTOINCREMENT,
+		  u         INTEGER NOT NULL,
+		  checklist TEXT NOT NULL,
+		  name      TEXT NOT NULL,
+		  v         BLOB NOT NULL,
+		  created   BIGINT NOT NULL DEFAULT '0',
+		  updated   BIGINT NOT NULL DEFAULT '0');`
+	if _, err := db.Exec(query); err != nil {
+		log.Fatal(err)
+	}
+	return &ProjectStore{db: db}
+}
+
+const projectColumns = "id, u, checklist, name, v, created, updated"
+
+func scanProject(row interface{ Scan(dest ...any) error }) (*models.SavedProject, error) {
+	var p models.SavedProject
+	var created, updated int64
+	if err := row.Scan(&p.ID, &p.UserID, &p.Checklist, &p.Name, &p.Values, &created, &updated); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	p.Created = time.Unix(created, 0).UTC()
+	p.Updated = time.Unix(updated, 0).UTC()
+	return &p, nil
+}
+
+// List returns the projects of a user for a checklist, the last updated
+// first.
+func (s *ProjectStore) List(userID int, checklist string) ([]*models.SavedProject, error) {
+	rows, err := s.db.Query(`SELECT `+projectColumns+` FROM projects
+		WHERE u = ? AND checklist = ? ORDER BY updated DESC, id DESC`, userID, checklist)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := make([]*models.SavedProject, 0)
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// Get returns a project of a user, ErrProjectNotFound if the user has no
+// project with that id.
+func (s *ProjectStore) Get(userID int, id int64) (*models.SavedProject, error) {
+	return scanProject(s.db.QueryRow(`SELECT `+projectColumns+` FROM projects
+		WHERE id = ? AND u = ?`, id, userID))
+}
+
+// Create saves a new project with the given answers.
+func (s *ProjectStore) Create(userID int, checklist string, name string, values []byte) (*models.SavedProject, error) {
+	now := time.Now().Unix()
+	res, err := s.db.Exec(`INSERT INTO projects (u, checklist, name, v, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?)`, userID, checklist, name, values, now, now)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(userID, id)
+}
+
+// Duplicate copies a project under a new name.
+func (s *ProjectStore) Duplicate(userID int, id int64, name string) (*models.SavedProject, error) {
+	p, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Create(userID, p.Checklist, name, p.Values)
+}
+
+// Save replaces the answers of a project.
+func (s *ProjectStore) Save(userID int, id int64, values []byte) error {
+	return s.update(`UPDATE projects SET v = ?, updated = ? WHERE id = ? AND u = ?`,
+		values, time.Now().Unix(), id, userID)
+}
+
+func (s *ProjectStore) Rename(userID int, id int64, name string) error {
+	return s.update(`UPDATE projects SET name = ?, updated = ? WHERE id = ? AND u = ?`,
+		name, time.Now().Unix(), id, userID)
+}
+
+func (s *ProjectStore) Delete(userID int, id int64) error {
+	return s.update(`DELETE FROM projects WHERE id = ? AND u = ?`, id, userID)
+}
+
+// update runs a statement changing a single project.
+func (s *ProjectStore) update(query string, args ...any) error {
+	res, err := s.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
@@ -3,22 +3,22 @@ package repository
 import (
 	"database/sql"
 	"encoding/gob"
-	"errors"
 	"log"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
-	"github.com/gofiber/fiber/v2/utils"
 	"github.com/gofiber/storage/sqlite3"
 
 	"terra9.it/checkmate/core"
-	"terra9.it/checkmate/server/models"
 )
 
+// DATABASE is the file of the server database.
+const DATABASE = "./db/fiber.db"
+
 func NewStorage() *sqlite3.Storage {
 	// Init SQLite3 database
-	db, err := sql.Open("sqlite3", "./db/fiber.db")
+	db, err := sql.Open("sqlite3", DATABASE)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -42,7 +42,7 @@ func NewStorage() *sqlite3.Storage {
 
 	// Init sessions store
 	storage := sqlite3.New(sqlite3.Config{
-		Database:        "./db/fiber.db",
+		Database:        DATABASE,
 		Table:           "sessions",
 		Reset:           false,
 		GCInterval:      60 * time.Second,
@@ -53,16 +53,13 @@ func NewStorage() *sqlite3.Storage {
 	return storage
 }
 
-// Simulate a database call
-func FindByCredentials(email, password string) (*models.User, error) {
-	// Here you would query your database for the user with the given email
-	if email == "test@mail.com" && password == "test12345" {
-		return &models.User{
-			ID:        1,
-			Email:     "test@mail.com",
-			Password:  "test12345",
-			SessionID: utils.UUIDv4(),
-		}, nil
+// openDatabase opens the server database for the stores.
+func openDatabase() *sql.DB {
+	db, err := sql.Open("sqlite3", DATABASE+"?_busy_timeout=5000")
+	if err != nil {
+		log.Fatal(err)
 	}
-	return nil, errors.New("user not found")
+	// the sessions storage writes the same file
+	db.SetMaxOpenConns(1)
+	return db
 }
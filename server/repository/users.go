@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2/utils"
+
+	"terra9.it/checkmate/server/models"
+)
+
+const (
+	// failed logins locking an account, and for how long
+	MAX_LOGIN_FAILURES = 5
+	LOCKOUT_DURATION   = 15 * time.Minute
+	// validity of the password reset tokens
+	RESET_TOKEN_DURATION = time.Hour
+)
+
+var (
+	// ErrInvalidCredentials does not tell unknown emails from wrong
+	// passwords nor from locked accounts
+	ErrInvalidCredentials = errors.New("invalid email or password, or too many failed logins: retry later")
+	// ErrAccountLocked is only returned to users proven to own the account
+	ErrAccountLocked     = errors.New("account locked after too many failed logins, retry later")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserExists        = errors.New("a user with this email already exists")
+	ErrInvalidEmail      = errors.New("invalid email")
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+)
+
+// Users is the store of the user accounts, set up by the server.
+var Users *UserStore
+
+// UserStore keeps the user accounts in the users table of the server
+// database.
+type UserStore struct {
+	db *sql.DB
+}
+
+func NewUserStore() *UserStore {
+	db := openDatabase()
+	query := `CREATE TABLE IF NOT EXISTS users (
+		  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		  email         TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		  password      TEXT NOT NULL,
+		  role          TEXT NOT NULL DEFAULT 'viewer',
+		  failures      INTEGER NOT NULL DEFAULT 0,
+		  locked        BIGINT NOT NULL DEFAULT '0',
+		  reset_token   TEXT NOT NULL DEFAULT '',
+		  reset_expires BIGINT NOT NULL DEFAULT '0',
+		  created       BIGINT NOT NULL DEFAULT '0');`
+	if _, err := db.Exec(query); err != nil {
+		log.Fatal(err)
+	}
+	return &UserStore{db: db}
+}
+
+const userColumns = "id, email, role, password, failures, locked, created"
+
+func scanUser(row interface{ Scan(dest ...any) error }) (*models.User, error) {
+	var u models.User
+	var locked, created int64
+	if err := row.Scan(&u.ID, &u.Email, &u.Role, &u.PasswordHash, &u.Failures, &locked, &created); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if locked > 0 {
+		u.LockedUntil = time.Unix(locked, 0).UTC()
+	}
+	u.Created = time.Unix(created, 0).UTC()
+	return &u, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (s *UserStore) Get(id int) (*models.User, error) {
+	return scanUser(s.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+}
+
+func (s *UserStore) GetByEmail(email string) (*models.User, error) {
+	return scanUser(s.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE email = ?`, normalizeEmail(email)))
+}
+
+// List returns the users by email.
+func (s *UserStore) List() ([]*models.User, error) {
+	rows, err := s.db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY email`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// Create adds a user account with one of models.Roles.
+func (s *UserStore) Create(email, password string, role string) (*models.User, error) {
+	email = normalizeEmail(email)
+	if !models.ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if at := strings.Index(email, "@"); at <= 0 || at == len(email)-1 {
+		return nil, ErrInvalidEmail
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetByEmail(email); err == nil {
+		return nil, ErrUserExists
+	}
+	res, err := s.db.Exec(`INSERT INTO users (email, password, role, created) VALUES (?, ?, ?, ?)`,
+		email, hash, role, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(int(id))
+}
+
+// CreateExternal adds a user logging in through an identity provider. The
+// password is random, until the user sets one with a reset token.
+func (s *UserStore) CreateExternal(email, role string) (*models.User, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return s.Create(email, hex.EncodeToString(b), role)
+}
+
+// dummyHash is checked against the passwords given with unknown emails,
+// so that they take as long as the others to be refused.
+var dummyHash = sync.OnceValue(func() string {
+	hash, err := HashPassword("dummy password")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return hash
+})
+
+// FindByCredentials returns the user with the email and password, with a
+// new session id. Failed logins are counted, and MAX_LOGIN_FAILURES of them
+// in a row lock the account for LOCKOUT_DURATION. Unknown emails, wrong
+// passwords and locked accounts all fail with ErrInvalidCredentials.
+func (s *UserStore) FindByCredentials(email, password string) (*models.User, error) {
+	user, err := s.GetByEmail(email)
+	if errors.Is(err, ErrUserNotFound) {
+		CheckPassword(dummyHash(), password)
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+
+	ok, err := CheckPassword(user.PasswordHash, password)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().Before(user.LockedUntil) {
+		return nil, ErrInvalidCredentials
+	}
+	if !ok {
+		// counted by the database, as concurrent logins may fail together
+		_, err := s.db.Exec(`UPDATE users SET
+			failures = CASE WHEN failures + 1 >= ? THEN 0 ELSE failures + 1 END,
+			locked = CASE WHEN failures + 1 >= ? THEN ? ELSE locked END
+			WHERE id = ?`,
+			MAX_LOGIN_FAILURES, MAX_LOGIN_FAILURES, time.Now().Add(LOCKOUT_DURATION).Unix(), user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.Failures > 0 || !user.LockedUntil.IsZero() {
+		if _, err := s.db.Exec(`UPDATE users SET failures = 0, locked = 0 WHERE id = ?`, user.ID); err != nil {
+			return nil, err
+		}
+		user.Failures, user.LockedUntil = 0, time.Time{}
+	}
+	user.SessionID = utils.UUIDv4()
+	return user, nil
+}
+
+// ChangePassword sets the password of a user who knows the current one.
+func (s *UserStore) ChangePassword(id int, password, newPassword string) error {
+	user, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if ok, err := CheckPassword(user.PasswordHash, password); err != nil {
+		return err
+	} else if !ok {
+		return ErrInvalidCredentials
+	}
+	return s.SetPassword(id, newPassword)
+}
+
+// SetPassword sets the password of a user, unlocking the account and
+// dropping any reset token.
+func (s *UserStore) SetPassword(id int, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.update(`UPDATE users SET password = ?, failures = 0, locked = 0, reset_token = '', reset_expires = 0
+		WHERE id = ?`, hash, id)
+}
+
+func (s *UserStore) SetRole(id int, role string) error {
+	if !models.ValidRole(role) {
+		return ErrInvalidRole
+	}
+	return s.update(`UPDATE users SET role = ? WHERE id = ?`, role, id)
+}
+
+func (s *UserStore) Unlock(id int) error {
+	return s.update(`UPDATE users SET failures = 0, locked = 0 WHERE id = ?`, id)
+}
+
+func (s *UserStore) Delete(id int) error {
+	return s.update(`DELETE FROM users WHERE id = ?`, id)
+}
+
+// NewResetToken returns a token letting the user set a new password within
+// RESET_TOKEN_DURATION. Only the hash of the token is stored.
+func (s *UserStore) NewResetToken(email string) (string, error) {
+	user, err := s.GetByEmail(email)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	err = s.update(`UPDATE users SET reset_token = ?, reset_expires = ? WHERE id = ?`,
+		hashToken(token), time.Now().Add(RESET_TOKEN_DURATION).Unix(), user.ID)
+	return token, err
+}
+
+// ResetPassword sets the password of the user a reset token was made for.
+func (s *UserStore) ResetPassword(token, password string) error {
+	var id int
+	err := s.db.QueryRow(`SELECT id FROM users WHERE reset_token = ? AND reset_expires > ?`,
+		hashToken(token), time.Now().Unix()).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) || token == "" {
+		return ErrInvalidResetToken
+	} else if err != nil {
+		return err
+	}
+	return s.SetPassword(id, password)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// update runs a statement changing a single user.
+func (s *UserStore) update(query string, args ...any) error {
+	res, err := s.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
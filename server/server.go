@@ -41,6 +41,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/spf13/viper"
+	"terra9.it/checkmate/server/admin"
 	"terra9.it/checkmate/server/handlers"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -53,6 +54,7 @@ import (
 	_ "terra9.it/checkmate/server/handlers/yaml"
 
 	"terra9.it/checkmate/server/middlewares"
+	"terra9.it/checkmate/server/repository"
 )
 
 var cfgFile string
@@ -75,6 +77,11 @@ func initConfig() {
 
 	viper.SetDefault("SERVER_HOST", "")
 	viper.SetDefault("SERVER_PORT", 4300)
+	viper.SetDefault("AUTH_REGISTRATION", false)
+	viper.SetDefault("AUTH_REGISTRATION_ROLE", "viewer")
+	viper.SetDefault("OIDC_SCOPES", "openid email profile")
+	viper.SetDefault("OIDC_CREATE_USERS", true)
+	viper.SetDefault("OIDC_DEFAULT_ROLE", "viewer")
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
@@ -129,6 +136,9 @@ func New(serverAddr string) {
 	key := viper.GetString("AUTH_SECRET")
 	app.Use(middlewares.NewAuthMiddleware(key))
 	app.Use(middlewares.NewSessionMiddleware(viper.GetString("SERVER_HOST")))
+	repository.Projects = repository.NewProjectStore()
+	repository.Users = repository.NewUserStore()
+	repository.Audit = repository.NewAuditStore()
 
 	route := app.Group("/api/v1")
 
@@ -153,5 +163,12 @@ func New(serverAddr string) {
 
 func main() {
 	initConfig()
+	if flag.NArg() > 0 {
+		if err := admin.Run(flag.Args()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	New(fmt.Sprintf("%v:%v", viper.GetString("SERVER_HOST"), viper.GetInt("SERVER_PORT")))
 }
@@ -10,6 +10,7 @@ import (
 	"path"
 	"runtime"
 
+	"terra9.it/checkmate/core"
 	"terra9.it/checkmate/loader"
 )
 
@@ -111,6 +112,26 @@ func mkPkg(name string, dataPath string) {
 	fmt.Println("closing zip archive...")
 	zipWriter.Close()
 
+	lintPkg(name, arcPath)
+}
+
+func lintPkg(name string, arcPath string) {
+	fmt.Println("linting " + arcPath + "...")
+	pkgLoader, err := loader.NewEmptyLoader(name).FromZip(arcPath)
+	if err != nil {
+		panic(err)
+	}
+	p := core.NewProject(pkgLoader)
+	errors := 0
+	for _, issue := range p.Lint() {
+		fmt.Println(issue)
+		if issue.Severity == core.LINT_ERROR {
+			errors++
+		}
+	}
+	if errors > 0 {
+		log.Fatalf("%s: %d lint errors", name, errors)
+	}
 }
 
 func addFile(zipWriter *zip.Writer, projFile string, name string) {
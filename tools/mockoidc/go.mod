@@ -0,0 +1,3 @@
+module terra9.it/checkmate/tools/mockoidc
+
+go 1.24.5
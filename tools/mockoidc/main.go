@@ -0,0 +1,247 @@
+// mockoidc is an OpenID Connect provider for testing the oidc login of the
+// server. It approves every authorization request for the configured user,
+// no login page involved. Run it with
+//
+//	mockoidc -addr 127.0.0.1:4398 -email user@example.com -role editor
+//
+// and set the server environment to
+//
+//	OIDC_ISSUER="http://127.0.0.1:4398"
+//	OIDC_CLIENT_ID="checkmate"
+//	OIDC_CLIENT_SECRET="secret"
+//	OIDC_REDIRECT_URL="http://<server>/api/v1/auth/oidc/callback"
+//	OIDC_ROLE_CLAIM="roles"
+//
+// A login_hint in the authorization request replaces the email.
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var (
+	addr         = flag.String("addr", "127.0.0.1:4398", "address to listen on")
+	clientID     = flag.String("client-id", "checkmate", "id of the client")
+	clientSecret = flag.String("client-secret", "secret", "secret of the client")
+	email        = flag.String("email", "user@example.com", "email of the user")
+	role         = flag.String("role", "", "role of the user, in the roles claim")
+	verified     = flag.Bool("verified", true, "email_verified claim")
+)
+
+const kid = "mock"
+
+// grant is what an authorization code stands for.
+type grant struct {
+	email       string
+	nonce       string
+	challenge   string
+	redirectURI string
+	expires     time.Time
+}
+
+var (
+	key    *rsa.PrivateKey
+	issuer string
+	grants = map[string]*grant{}
+	mu     sync.Mutex
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func tokenError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func jwks(w http.ResponseWriter, r *http.Request) {
+	pub := key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   b64(pub.N.Bytes()),
+			"e":   b64(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize approves the request and redirects back with a code.
+func authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("client_id") != *clientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	back := redirect.Query()
+	back.Set("state", q.Get("state"))
+	if q.Get("response_type") != "code" {
+		back.Set("error", "unsupported_response_type")
+	} else if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		back.Set("error", "invalid_request")
+		back.Set("error_description", "S256 code challenge required")
+	} else {
+		g := &grant{
+			email:       *email,
+			nonce:       q.Get("nonce"),
+			challenge:   q.Get("code_challenge"),
+			redirectURI: q.Get("redirect_uri"),
+			expires:     time.Now().Add(time.Minute),
+		}
+		if hint := q.Get("login_hint"); hint != "" {
+			g.email = hint
+		}
+		b := make([]byte, 16)
+		rand.Read(b)
+		code := b64(b)
+		mu.Lock()
+		grants[code] = g
+		mu.Unlock()
+		back.Set("code", code)
+	}
+	redirect.RawQuery = back.Encode()
+	log.Println("authorize", q.Get("login_hint"), redirect)
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// token trades a code for an id token, once.
+func token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		tokenError(w, http.StatusMethodNotAllowed, "invalid_request", "POST required")
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if ok {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		id, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if id != *clientID || subtle.ConstantTimeCompare([]byte(secret), []byte(*clientSecret)) != 1 {
+		tokenError(w, http.StatusUnauthorized, "invalid_client", "")
+		return
+	}
+	if r.PostFormValue("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+		return
+	}
+
+	code := r.PostFormValue("code")
+	mu.Lock()
+	g := grants[code]
+	delete(grants, code)
+	mu.Unlock()
+	if g == nil || time.Now().After(g.expires) || g.redirectURI != r.PostFormValue("redirect_uri") {
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "unknown or expired code")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if b64(sum[:]) != g.challenge {
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "code verifier mismatch")
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss":            issuer,
+		"sub":            g.email,
+		"aud":            *clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          g.nonce,
+		"email":          g.email,
+		"email_verified": *verified,
+	}
+	if *role != "" {
+		claims["roles"] = []string{*role}
+	}
+	idToken, err := sign(claims)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": b64(sum[:8]),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// sign returns the RS256 JWT of the claims.
+func sign(claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := b64(header) + "." + b64(payload)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + b64(sig), nil
+}
+
+func main() {
+	flag.Parse()
+	var err error
+	if key, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		log.Fatal(err)
+	}
+	issuer = "http://" + *addr
+
+	http.HandleFunc("/.well-known/openid-configuration", discovery)
+	http.HandleFunc("/jwks", jwks)
+	http.HandleFunc("/authorize", authorize)
+	http.HandleFunc("/token", token)
+	log.Println("issuer", issuer)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}